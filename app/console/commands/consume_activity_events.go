@@ -2,12 +2,16 @@ package commands
 
 import (
 	"context"
+	"errors"
+	"os/signal"
+	"strings"
+	"syscall"
+
 	"goravel/app/services"
 
 	"github.com/goravel/framework/contracts/console"
 	"github.com/goravel/framework/contracts/console/command"
 	"github.com/goravel/framework/facades"
-	"github.com/segmentio/kafka-go"
 )
 
 type ConsumeActivityEvents struct {
@@ -27,6 +31,12 @@ func (receiver *ConsumeActivityEvents) Description() string {
 func (receiver *ConsumeActivityEvents) Extend() command.Extend {
 	return command.Extend{
 		Category: "kafka",
+		Flags: []command.Flag{
+			&command.StringFlag{
+				Name:  "topics",
+				Usage: "Comma-separated topics to consume instead of kafka.consumer_topics",
+			},
+		},
 	}
 }
 
@@ -34,22 +44,58 @@ func (receiver *ConsumeActivityEvents) Extend() command.Extend {
 func (receiver *ConsumeActivityEvents) Handle(ctx console.Context) error {
 	facades.Log().Info("Starting Kafka activity event consumer...")
 
-	kafkaService := services.GetKafkaService()
+	broker, err := services.Broker()
+	if err != nil {
+		ctx.Error("Failed to start the consumer: " + err.Error())
+		return err
+	}
+
+	// Stop consuming on SIGINT/SIGTERM so in-flight messages can drain before exit
+	consumerCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if !kafkaService.IsEnabled() {
-		facades.Log().Warning("Kafka service is disabled. Cannot start consumer.")
+	// Wait for the brokers if the service is still reconnecting
+	if !broker.IsEnabled() {
+		facades.Log().Warning("Kafka is unavailable. Waiting for the connection before consuming...")
+	}
+	if err := broker.WaitForConnection(consumerCtx); err != nil {
+		if errors.Is(err, services.ErrKafkaDisabled) {
+			facades.Log().Warning("Kafka service is disabled. Cannot start consumer.")
+		}
 		return nil
 	}
 
-	// Create a context that can be cancelled
-	consumerCtx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	defer func() {
+		if err := broker.Close(); err != nil {
+			facades.Log().Error("Failed to close Kafka connections: " + err.Error())
+		}
+	}()
+
+	// The consumer runs no HTTP server, so it samples and serves its reader metrics itself
+	if kafkaService, ok := broker.(*services.KafkaService); ok {
+		kafkaService.StartSampling()
+		if addr := facades.Config().GetString("kafka.metrics.consumer_addr", ""); addr != "" {
+			go func() {
+				if err := kafkaService.ServeMetrics(consumerCtx, addr); err != nil {
+					facades.Log().Error("Kafka consumer metrics server failed: " + err.Error())
+				}
+			}()
+		}
+	}
 
 	// Start consuming messages
-	err := kafkaService.ConsumeMessages(consumerCtx, func(msg *kafka.Message) error {
-		// Process the activity event
-		return kafkaService.ProcessActivityEvent(msg)
-	})
+	// Events are dispatched to the handlers registered in services.EventHandlers()
+	topics := broker.ConsumerTopics()
+	if option := ctx.Option("topics"); option != "" {
+		topics = nil
+		for _, topic := range strings.Split(option, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	err = broker.Consume(consumerCtx, topics)
 
 	if err != nil {
 		facades.Log().Error("Kafka consumer error: " + err.Error())
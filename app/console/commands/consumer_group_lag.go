@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+)
+
+type ConsumerGroupLag struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *ConsumerGroupLag) Signature() string {
+	return "kafka:consumer-groups:lag"
+}
+
+// Description The console command description.
+func (receiver *ConsumerGroupLag) Description() string {
+	return "Show how far a consumer group is behind on each partition"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *ConsumerGroupLag) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.StringFlag{
+				Name:  "group",
+				Usage: "Consumer group ID (defaults to kafka.consumer_group_id)",
+			},
+			&command.StringFlag{
+				Name:  "topics",
+				Usage: "Comma-separated topics to check (defaults to the consumed topics)",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *ConsumerGroupLag) Handle(ctx console.Context) error {
+	kafkaService := services.GetKafkaService()
+
+	group := ctx.Option("group")
+	if group == "" {
+		group = kafkaService.Config().ConsumerGroupID
+	}
+	topics := kafkaService.ConsumerTopics()
+	if option := ctx.Option("topics"); option != "" {
+		topics = nil
+		for _, topic := range strings.Split(option, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics = append(topics, topic)
+			}
+		}
+	}
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	lags, err := kafkaService.ConsumerGroupLag(requestCtx, group, topics)
+	if err != nil {
+		ctx.Error("Failed to fetch consumer group offsets: " + err.Error())
+		return err
+	}
+	if len(lags) == 0 {
+		ctx.Warning("None of the topics exist: " + strings.Join(topics, ", "))
+		return nil
+	}
+
+	ctx.Info("Consumer group: " + group)
+	totals := map[string]int64{}
+	var order []string
+	for _, lag := range lags {
+		if _, ok := totals[lag.Topic]; !ok {
+			order = append(order, lag.Topic)
+		}
+		totals[lag.Topic] += lag.Lag
+
+		committed := fmt.Sprint(lag.CommittedOffset)
+		if lag.CommittedOffset < 0 {
+			committed = "none"
+		}
+		ctx.Line(fmt.Sprintf("  %s/%d: committed %s, end %d, lag %d", lag.Topic, lag.Partition, committed, lag.LastOffset, lag.Lag))
+	}
+
+	for _, topic := range order {
+		ctx.Info(fmt.Sprintf("%s: total lag %d", topic, totals[topic]))
+	}
+	return nil
+}
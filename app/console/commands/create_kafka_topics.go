@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+)
+
+type CreateKafkaTopics struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *CreateKafkaTopics) Signature() string {
+	return "kafka:topics:create"
+}
+
+// Description The console command description.
+func (receiver *CreateKafkaTopics) Description() string {
+	return "Create the Kafka topics this service depends on using the layout in kafka.topics"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *CreateKafkaTopics) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.StringFlag{
+				Name:  "topics",
+				Usage: "Comma-separated topics to create (defaults to every managed topic)",
+			},
+			&command.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Validate the topics with the brokers without creating them",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *CreateKafkaTopics) Handle(ctx console.Context) error {
+	kafkaService := services.GetKafkaService()
+
+	specs := kafkaService.ManagedTopics()
+	if option := ctx.Option("topics"); option != "" {
+		managed := map[string]services.TopicSpec{}
+		for _, spec := range specs {
+			managed[spec.Name] = spec
+		}
+
+		specs = nil
+		for _, name := range strings.Split(option, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			spec, ok := managed[name]
+			if !ok {
+				// Topics outside the managed set use the default layout
+				spec = services.TopicSpecFor(name)
+			}
+			specs = append(specs, spec)
+		}
+	}
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dryRun := ctx.OptionBool("dry-run")
+	results, err := kafkaService.CreateTopics(requestCtx, specs, dryRun)
+	if err != nil {
+		ctx.Error("Failed to create topics: " + err.Error())
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		layout := fmt.Sprintf("%d partitions, replication factor %d, retention %s",
+			result.Spec.Partitions, result.Spec.ReplicationFactor, time.Duration(result.Spec.RetentionMs)*time.Millisecond)
+		switch {
+		case result.Error != nil:
+			failed++
+			ctx.Error(fmt.Sprintf("✗ %s: %s", result.Spec.Name, result.Error))
+		case !result.Created:
+			ctx.Line(fmt.Sprintf("- %s: already exists", result.Spec.Name))
+		case dryRun:
+			ctx.Success(fmt.Sprintf("✓ %s: valid (%s)", result.Spec.Name, layout))
+		default:
+			ctx.Success(fmt.Sprintf("✓ %s: created (%s)", result.Spec.Name, layout))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d topics could not be created", failed)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+)
+
+type DescribeKafkaTopic struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *DescribeKafkaTopic) Signature() string {
+	return "kafka:topics:describe"
+}
+
+// Description The console command description.
+func (receiver *DescribeKafkaTopic) Description() string {
+	return "Show a Kafka topic's partitions, replicas, offsets and configuration"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *DescribeKafkaTopic) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.BoolFlag{
+				Name:  "all-configs",
+				Usage: "Print every topic config instead of the commonly tuned ones",
+			},
+		},
+	}
+}
+
+// describedConfigs are the topic configs printed by default
+var describedConfigs = []string{"cleanup.policy", "retention.ms", "retention.bytes", "min.insync.replicas", "max.message.bytes", "segment.ms"}
+
+// Handle Execute the console command.
+func (receiver *DescribeKafkaTopic) Handle(ctx console.Context) error {
+	name := ctx.Argument(0)
+	if name == "" {
+		ctx.Error("Usage: kafka:topics:describe <topic>")
+		return errors.New("topic name is required")
+	}
+
+	kafkaService := services.GetKafkaService()
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	description, err := kafkaService.DescribeTopic(requestCtx, name)
+	if err != nil {
+		ctx.Error("Failed to describe topic: " + err.Error())
+		return err
+	}
+
+	ctx.Info(fmt.Sprintf("Topic: %s (%d partitions, replication factor %d)", description.Name, len(description.Partitions), description.ReplicationFactor))
+	ctx.Info("Partitions:")
+	for _, partition := range description.Partitions {
+		line := fmt.Sprintf("  %d: leader %d, replicas %v, isr %v, offsets %d-%d (%d messages)",
+			partition.ID, partition.Leader, partition.Replicas, partition.ISR,
+			partition.FirstOffset, partition.LastOffset, partition.LastOffset-partition.FirstOffset)
+		if len(partition.ISR) < len(partition.Replicas) {
+			ctx.Warning(line + " - under-replicated")
+		} else {
+			ctx.Line(line)
+		}
+	}
+
+	ctx.Info("Configs:")
+	names := describedConfigs
+	if ctx.OptionBool("all-configs") {
+		names = make([]string, 0, len(description.Configs))
+		for config := range description.Configs {
+			names = append(names, config)
+		}
+		sort.Strings(names)
+	}
+	for _, config := range names {
+		if value, ok := description.Configs[config]; ok {
+			ctx.Line(fmt.Sprintf("  %s = %s", config, value))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+)
+
+type ListKafkaTopics struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *ListKafkaTopics) Signature() string {
+	return "kafka:topics:list"
+}
+
+// Description The console command description.
+func (receiver *ListKafkaTopics) Description() string {
+	return "List Kafka topics with their partition count and replication factor"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *ListKafkaTopics) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.BoolFlag{
+				Name:  "managed",
+				Usage: "Only list the topics this service depends on, including missing ones",
+			},
+			&command.BoolFlag{
+				Name:  "internal",
+				Usage: "Include internal topics such as __consumer_offsets",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *ListKafkaTopics) Handle(ctx console.Context) error {
+	kafkaService := services.GetKafkaService()
+
+	requestCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	topics, err := kafkaService.ListTopics(requestCtx)
+	if err != nil {
+		ctx.Error("Failed to list topics: " + err.Error())
+		return err
+	}
+
+	if !ctx.OptionBool("managed") {
+		for _, topic := range topics {
+			if topic.Internal && !ctx.OptionBool("internal") {
+				continue
+			}
+			ctx.Line(fmt.Sprintf("%s (%d partitions, replication factor %d)", topic.Name, topic.Partitions, topic.ReplicationFactor))
+		}
+		return nil
+	}
+
+	existing := map[string]services.TopicInfo{}
+	for _, topic := range topics {
+		existing[topic.Name] = topic
+	}
+	for _, spec := range kafkaService.ManagedTopics() {
+		topic, ok := existing[spec.Name]
+		switch {
+		case !ok:
+			ctx.Error(fmt.Sprintf("✗ %s: missing (run kafka:topics:create)", spec.Name))
+		case topic.Partitions != spec.Partitions || topic.ReplicationFactor != spec.ReplicationFactor:
+			ctx.Warning(fmt.Sprintf("! %s: %d partitions, replication factor %d (configured %d, %d)",
+				spec.Name, topic.Partitions, topic.ReplicationFactor, spec.Partitions, spec.ReplicationFactor))
+		default:
+			ctx.Success(fmt.Sprintf("✓ %s: %d partitions, replication factor %d", spec.Name, topic.Partitions, topic.ReplicationFactor))
+		}
+	}
+
+	return nil
+}
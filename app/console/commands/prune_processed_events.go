@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"strconv"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+)
+
+type PruneProcessedEvents struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *PruneProcessedEvents) Signature() string {
+	return "kafka:prune-processed-events"
+}
+
+// Description The console command description.
+func (receiver *PruneProcessedEvents) Description() string {
+	return "Remove processed event records older than the dedup retention window"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *PruneProcessedEvents) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *PruneProcessedEvents) Handle(ctx console.Context) error {
+	pruned, err := services.NewProcessedEventStore().Prune()
+	if err != nil {
+		ctx.Error("Failed to prune processed events: " + err.Error())
+		return err
+	}
+
+	ctx.Info("Pruned " + strconv.FormatInt(pruned, 10) + " processed event records")
+	return nil
+}
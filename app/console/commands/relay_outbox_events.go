@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+	"github.com/goravel/framework/facades"
+)
+
+type RelayOutboxEvents struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *RelayOutboxEvents) Signature() string {
+	return "kafka:outbox-relay"
+}
+
+// Description The console command description.
+func (receiver *RelayOutboxEvents) Description() string {
+	return "Relay pending outbox events to Kafka"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *RelayOutboxEvents) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.BoolFlag{
+				Name:  "once",
+				Usage: "Relay a single batch and exit",
+			},
+			&command.BoolFlag{
+				Name:  "retry-failed",
+				Usage: "Requeue events that exhausted their delivery attempts before relaying",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *RelayOutboxEvents) Handle(ctx console.Context) error {
+	publisher, err := services.Publisher()
+	if err != nil {
+		ctx.Error("Failed to start the outbox relay: " + err.Error())
+		return err
+	}
+	relay := services.NewOutboxRelay(publisher)
+
+	if ctx.OptionBool("retry-failed") {
+		requeued, err := relay.RetryFailed()
+		if err != nil {
+			ctx.Error("Failed to requeue outbox events: " + err.Error())
+			return err
+		}
+		ctx.Info("Requeued " + strconv.FormatInt(requeued, 10) + " failed outbox events")
+	}
+
+	relayCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if ctx.OptionBool("once") {
+		sent, err := relay.RelayBatch(relayCtx)
+		if err != nil {
+			ctx.Error("Outbox relay failed: " + err.Error())
+			return err
+		}
+		ctx.Success("Relayed " + strconv.Itoa(sent) + " outbox events")
+		return nil
+	}
+
+	facades.Log().Info("Starting Kafka outbox relay...")
+	if err := relay.Run(relayCtx); err != nil {
+		facades.Log().Error("Outbox relay error: " + err.Error())
+		return err
+	}
+
+	facades.Log().Info("Kafka outbox relay stopped")
+	return nil
+}
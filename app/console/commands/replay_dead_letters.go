@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+	"github.com/goravel/framework/facades"
+)
+
+type ReplayDeadLetters struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *ReplayDeadLetters) Signature() string {
+	return "kafka:dlq-replay"
+}
+
+// Description The console command description.
+func (receiver *ReplayDeadLetters) Description() string {
+	return "Re-publish dead-lettered messages to their original topic"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *ReplayDeadLetters) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.StringFlag{
+				Name:  "topic",
+				Usage: "Source topic whose dead-letter topic is replayed (defaults to the activity events topic)",
+			},
+			&command.IntFlag{
+				Name:  "limit",
+				Usage: "Maximum number of messages to replay (0 for all)",
+			},
+			&command.IntFlag{
+				Name:  "idle-timeout",
+				Value: 10,
+				Usage: "Seconds to wait for another message before stopping",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *ReplayDeadLetters) Handle(ctx console.Context) error {
+	kafkaService := services.GetKafkaService()
+
+	if !kafkaService.IsEnabled() {
+		ctx.Error("Kafka service is disabled. Cannot replay dead letters.")
+		return nil
+	}
+
+	topic := ctx.Option("topic")
+	if topic == "" {
+		topic = facades.Config().GetString("kafka.activity_events_topic", "activity-events")
+	}
+
+	idleTimeout := time.Duration(ctx.OptionInt("idle-timeout")) * time.Second
+	if idleTimeout < time.Second {
+		ctx.Error("--idle-timeout must be at least 1 second")
+		return nil
+	}
+
+	replayCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ctx.Info("Replaying dead letters for topic: " + topic)
+	replayed, err := kafkaService.ReplayDeadLetters(replayCtx, topic, ctx.OptionInt("limit"), idleTimeout)
+	if err != nil {
+		ctx.Error("Dead letter replay stopped after " + strconv.Itoa(replayed) + " messages: " + err.Error())
+		return err
+	}
+
+	ctx.Success("Replayed " + strconv.Itoa(replayed) + " dead-lettered messages")
+	return nil
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/contracts/console"
+	"github.com/goravel/framework/contracts/console/command"
+)
+
+type ReplayEvents struct {
+}
+
+// Signature The name and signature of the console command.
+func (receiver *ReplayEvents) Signature() string {
+	return "kafka:replay"
+}
+
+// Description The console command description.
+func (receiver *ReplayEvents) Description() string {
+	return "Re-run the event handlers over past events to rebuild derived data"
+}
+
+// Extend The application provides several methods that help you interact with the user.
+func (receiver *ReplayEvents) Extend() command.Extend {
+	return command.Extend{
+		Category: "kafka",
+		Flags: []command.Flag{
+			&command.StringFlag{
+				Name:  "topics",
+				Usage: "Comma-separated topics to replay (defaults to the consumed topics)",
+			},
+			&command.BoolFlag{
+				Name:  "from-beginning",
+				Usage: "Start at the oldest retained message of every partition",
+			},
+			&command.StringFlag{
+				Name:  "from-offset",
+				Usage: "Start at this offset on every partition",
+			},
+			&command.StringFlag{
+				Name:  "from-time",
+				Usage: "Start at the first message at or after this RFC 3339 time, e.g. 2024-05-01T00:00:00Z",
+			},
+			&command.StringFlag{
+				Name:  "group",
+				Usage: "Resume an earlier replay by its consumer group instead of starting a new one",
+			},
+			&command.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Decode and count the events without running handlers",
+			},
+			&command.IntFlag{
+				Name:  "limit",
+				Usage: "Maximum number of events to replay (0 for all)",
+			},
+			&command.IntFlag{
+				Name:  "idle-timeout",
+				Value: 10,
+				Usage: "Seconds to wait for another message before stopping",
+			},
+		},
+	}
+}
+
+// Handle Execute the console command.
+func (receiver *ReplayEvents) Handle(ctx console.Context) error {
+	kafkaService := services.GetKafkaService()
+
+	if !kafkaService.IsEnabled() {
+		ctx.Error("Kafka service is disabled. Cannot replay events.")
+		return nil
+	}
+
+	options := services.ReplayOptions{
+		Topics:        kafkaService.ConsumerTopics(),
+		FromBeginning: ctx.OptionBool("from-beginning"),
+		GroupID:       ctx.Option("group"),
+		DryRun:        ctx.OptionBool("dry-run"),
+		Limit:         ctx.OptionInt("limit"),
+		IdleTimeout:   time.Duration(ctx.OptionInt("idle-timeout")) * time.Second,
+	}
+	if option := ctx.Option("topics"); option != "" {
+		options.Topics = nil
+		for _, topic := range strings.Split(option, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				options.Topics = append(options.Topics, topic)
+			}
+		}
+	}
+	if option := ctx.Option("from-offset"); option != "" {
+		offset, err := strconv.ParseInt(option, 10, 64)
+		if err != nil || offset < 0 {
+			ctx.Error("--from-offset must be a non-negative integer")
+			return nil
+		}
+		options.FromOffset = &offset
+	}
+	if option := ctx.Option("from-time"); option != "" {
+		from, err := time.Parse(time.RFC3339, option)
+		if err != nil {
+			ctx.Error("--from-time must be an RFC 3339 time: " + err.Error())
+			return nil
+		}
+		options.FromTime = &from
+	}
+	if options.IdleTimeout < time.Second {
+		ctx.Error("--idle-timeout must be at least 1 second")
+		return nil
+	}
+
+	starts := 0
+	for _, set := range []bool{options.FromBeginning, options.FromOffset != nil, options.FromTime != nil} {
+		if set {
+			starts++
+		}
+	}
+	switch {
+	case options.GroupID != "" && starts > 0:
+		ctx.Error("--group resumes a replay where it stopped and cannot be combined with --from-*")
+		return nil
+	case options.GroupID == "" && starts != 1:
+		ctx.Error("Choose where to start with exactly one of --from-beginning, --from-offset or --from-time")
+		return nil
+	}
+
+	replayCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if options.DryRun {
+		ctx.Warning("Dry run: events are decoded but no handlers run")
+	}
+	ctx.Info("Replaying topics: " + strings.Join(options.Topics, ", "))
+	result, err := kafkaService.Replay(replayCtx, options)
+	if result != nil {
+		ctx.Info("Replay consumer group: " + result.GroupID)
+		eventTypes := make([]string, 0, len(result.EventTypes))
+		for eventType := range result.EventTypes {
+			eventTypes = append(eventTypes, eventType)
+		}
+		sort.Strings(eventTypes)
+		for _, eventType := range eventTypes {
+			ctx.Line(fmt.Sprintf("  %s: %d", eventType, result.EventTypes[eventType]))
+		}
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			ctx.Warning("Replay interrupted; pass --group to resume it")
+			return nil
+		}
+		ctx.Error("Replay failed: " + err.Error())
+		return err
+	}
+
+	summary := fmt.Sprintf("Read %d events, %d handled, %d failed", result.Read, result.Handled, result.Failed)
+	if result.Failed > 0 {
+		ctx.Warning(summary + " (see the log for the failures)")
+		return nil
+	}
+	ctx.Success(summary)
+	return nil
+}
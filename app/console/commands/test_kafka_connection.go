@@ -1,6 +1,11 @@
 package commands
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"goravel/app/services"
 
 	"github.com/goravel/framework/contracts/console"
@@ -18,7 +23,7 @@ func (receiver *TestKafkaConnection) Signature() string {
 
 // Description The console command description.
 func (receiver *TestKafkaConnection) Description() string {
-	return "Test the Kafka connection and configuration"
+	return "Check Kafka broker reachability and topic state without producing data"
 }
 
 // Extend The application provides several methods that help you interact with the user.
@@ -34,19 +39,57 @@ func (receiver *TestKafkaConnection) Handle(ctx console.Context) error {
 
 	kafkaService := services.GetKafkaService()
 
+	config := kafkaService.Config()
 	ctx.Info("Kafka Configuration:")
-	ctx.Info("Profile: " + facades.Config().GetString("kafka.profile", "local"))
-	ctx.Info("Bootstrap Servers: " + facades.Config().GetString("kafka.bootstrap_servers", "not configured"))
-	ctx.Info("Security Protocol: " + facades.Config().GetString("kafka.security_protocol", "PLAINTEXT"))
-	ctx.Info("Activity Events Topic: " + facades.Config().GetString("kafka.activity_events_topic", "activity-events"))
-	ctx.Info("Consumer Group ID: " + facades.Config().GetString("kafka.consumer_group_id", "activity-service-consumers"))
+	ctx.Info("Profile: " + config.Profile)
+	ctx.Info("Bootstrap Servers: " + config.BootstrapServers)
+	ctx.Info("Security Protocol: " + config.SecurityProtocol)
+	if strings.HasPrefix(config.SecurityProtocol, "SASL_") {
+		ctx.Info("SASL Mechanism: " + config.SASLMechanism)
+	}
+	ctx.Info("Activity Events Topic: " + config.ActivityEventsTopic)
+	ctx.Info("Consumer Group ID: " + config.ConsumerGroupID)
 	ctx.Line("")
 
-	if kafkaService.IsEnabled() {
-		ctx.Success("✓ Kafka service is enabled and connected")
-	} else {
-		ctx.Error("✗ Kafka service is disabled or failed to connect")
+	probeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report, err := kafkaService.CheckHealth(probeCtx, kafkaService.HealthTopics())
+	if err != nil {
+		ctx.Error("✗ Could not fetch cluster metadata: " + err.Error())
 		ctx.Info("This is usually due to network issues or incorrect configuration")
+		return nil
+	}
+
+	ctx.Info("Brokers:")
+	for _, broker := range report.Brokers {
+		if broker.Reachable {
+			ctx.Success(fmt.Sprintf("  ✓ %d %s (%d APIs, %dms)", broker.ID, broker.Address, broker.APIVersions, broker.LatencyMs))
+		} else {
+			ctx.Error(fmt.Sprintf("  ✗ %d %s: %s", broker.ID, broker.Address, broker.Error))
+		}
+	}
+
+	ctx.Info("Topics:")
+	for _, topic := range report.Topics {
+		switch {
+		case topic.Error != "":
+			ctx.Error(fmt.Sprintf("  ✗ %s: %s", topic.Name, topic.Error))
+		case len(topic.LeaderlessPartitions) > 0:
+			ctx.Error(fmt.Sprintf("  ✗ %s: %d partitions, no leader for %v", topic.Name, topic.Partitions, topic.LeaderlessPartitions))
+		default:
+			ctx.Success(fmt.Sprintf("  ✓ %s: %d partitions, all with leaders", topic.Name, topic.Partitions))
+		}
+	}
+	ctx.Line("")
+
+	if report.Healthy {
+		ctx.Success("✓ Kafka cluster is reachable and all topics are ready")
+	} else {
+		ctx.Error("✗ Kafka cluster has unreachable brokers or topics that are not ready")
+	}
+	if !kafkaService.IsEnabled() {
+		ctx.Warning("Kafka was disabled when this process started; events are buffered in the outbox until it reconnects")
 	}
 
 	return nil
@@ -5,18 +5,29 @@ import (
 
 	"github.com/goravel/framework/contracts/console"
 	"github.com/goravel/framework/contracts/schedule"
+	"github.com/goravel/framework/facades"
 )
 
 type Kernel struct {
 }
 
 func (kernel Kernel) Schedule() []schedule.Event {
-	return []schedule.Event{}
+	return []schedule.Event{
+		facades.Schedule().Command("kafka:prune-processed-events").Hourly(),
+	}
 }
 
 func (kernel Kernel) Commands() []console.Command {
 	return []console.Command{
 		&commands.ConsumeActivityEvents{},
 		&commands.TestKafkaConnection{},
+		&commands.RelayOutboxEvents{},
+		&commands.PruneProcessedEvents{},
+		&commands.ReplayDeadLetters{},
+		&commands.ListKafkaTopics{},
+		&commands.CreateKafkaTopics{},
+		&commands.DescribeKafkaTopic{},
+		&commands.ConsumerGroupLag{},
+		&commands.ReplayEvents{},
 	}
 }
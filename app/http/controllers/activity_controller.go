@@ -1,23 +1,27 @@
 package controllers
 
 import (
+	"errors"
+	"goravel/app/http/requests"
 	"goravel/app/models"
 	"goravel/app/services"
 	"strconv"
 	"time"
 
+	"github.com/goravel/framework/contracts/database/orm"
 	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/contracts/validation"
 	"github.com/goravel/framework/facades"
 )
 
+// errInvalidUpdate stops an update that is invalid for the activity it applies to
+var errInvalidUpdate = errors.New("invalid activity update")
+
 type ActivityController struct {
-	kafkaService *services.KafkaService
 }
 
 func NewActivityController() *ActivityController {
-	return &ActivityController{
-		kafkaService: services.GetKafkaService(),
-	}
+	return &ActivityController{}
 }
 
 // Index returns a list of activities with optional filtering
@@ -130,19 +134,22 @@ func (r *ActivityController) Store(ctx http.Context) http.Response {
 		activity.Status = "active"
 	}
 
-	// Create activity
-	if err := facades.Orm().Query().Create(&activity); err != nil {
+	// Create activity and record its revision and event atomically
+	err := facades.Orm().Transaction(func(tx orm.Query) error {
+		if err := tx.Create(&activity); err != nil {
+			return err
+		}
+		if err := services.RecordActivityRevision(tx, activity.ID, models.RevisionCreated, services.DiffActivity(nil, &activity), claimedActor(ctx)); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "activity.created", services.NewActivityPayload(activity), traceContext(ctx))
+	})
+	if err != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": err.Error(),
 		})
 	}
 
-	// Publish event to Kafka
-	if err := r.kafkaService.PublishActivityCreated(activity); err != nil {
-		facades.Log().Error("Failed to publish activity created event: " + err.Error())
-		// Don't return error - the activity was created successfully
-	}
-
 	return ctx.Response().Status(201).Json(map[string]any{
 		"message": "Activity created successfully",
 		"data":    activity,
@@ -154,7 +161,7 @@ func (r *ActivityController) Show(ctx http.Context) http.Response {
 	id := ctx.Request().Route("id")
 	var activity models.Activity
 
-	if err := facades.Orm().Query().Where("id = ?", id).First(&activity).Error; err != nil {
+	if err := facades.Orm().Query().Where("id = ?", id).FirstOrFail(&activity); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Activity not found",
 		})
@@ -165,44 +172,85 @@ func (r *ActivityController) Show(ctx http.Context) http.Response {
 	})
 }
 
-// Update updates an activity
+// Update applies a partial update to an activity. Only the fields of
+// requests.UpdateActivityRequest can be changed; invalid values get a 422
+// listing the errors per field.
 func (r *ActivityController) Update(ctx http.Context) http.Response {
 	id := ctx.Request().Route("id")
-	var activity models.Activity
+
+	var request requests.UpdateActivityRequest
+	errs, err := ctx.Request().ValidateRequest(&request)
+	if err != nil {
+		return ctx.Response().Status(400).Json(map[string]any{
+			"error": "Invalid request body",
+		})
+	}
+	if errs != nil {
+		return validationFailed(ctx, errs)
+	}
 
 	// Find existing activity
-	if err := facades.Orm().Query().Where("id = ?", id).First(&activity).Error; err != nil {
+	var activity models.Activity
+	if err := facades.Orm().Query().Where("id = ?", id).FirstOrFail(&activity); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Activity not found",
 		})
 	}
 
-	// Parse request body
-	var updateData map[string]any
-	if err := ctx.Request().Bind(&updateData); err != nil {
-		return ctx.Response().Status(400).Json(map[string]any{
-			"error": "Invalid request body",
-		})
-	}
+	var invalid validation.Errors
+	err = facades.Orm().Transaction(func(tx orm.Query) error {
+		// Lock the row so the diff is against the version being replaced
+		var original models.Activity
+		if err := tx.LockForUpdate().Where("id = ?", id).First(&original); err != nil {
+			return err
+		}
 
-	// Update the activity with new data
-	// Note: We update each field from the map
-	for key, value := range updateData {
-		_, err := facades.Orm().Query().Model(&activity).Update(key, value)
+		activity = original
+		values, err := request.Apply(ctx, &activity)
 		if err != nil {
-			return ctx.Response().Status(500).Json(map[string]any{
-				"error": err.Error(),
-			})
+			return err
+		}
+		errs, err := request.ValidateActivity(&activity)
+		if err != nil {
+			return err
+		}
+		if errs != nil {
+			invalid = errs
+			return errInvalidUpdate
 		}
-	}
 
-	// Refresh the model to get updated values
-	facades.Orm().Query().Where("id = ?", id).First(&activity)
+		// Nothing to record when every field keeps its value
+		if len(services.DiffActivity(&original, &activity)) == 0 {
+			activity = original
+			return nil
+		}
 
-	// Publish event to Kafka
-	if err := r.kafkaService.PublishActivityUpdated(activity); err != nil {
-		facades.Log().Error("Failed to publish activity updated event: " + err.Error())
-		// Don't return error - the activity was updated successfully
+		// Write every field in one statement. Load the updated values into a
+		// new model; scanning into the old one would merge metadata into the
+		// map it shares with the loaded version.
+		if _, err := tx.Model(&models.Activity{}).Where("id = ?", id).Update(values); err != nil {
+			return err
+		}
+		var updated models.Activity
+		if err := tx.Where("id = ?", id).First(&updated); err != nil {
+			return err
+		}
+		activity = updated
+
+		// Record what changed; the event carries the same diff
+		changes := services.DiffActivity(&original, &updated)
+		if err := services.RecordActivityRevision(tx, updated.ID, models.RevisionUpdated, changes, claimedActor(ctx)); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "activity.updated", services.NewActivityUpdatedPayload(updated, changes), traceContext(ctx))
+	})
+	if errors.Is(err, errInvalidUpdate) {
+		return validationFailed(ctx, invalid)
+	}
+	if err != nil {
+		return ctx.Response().Status(500).Json(map[string]any{
+			"error": err.Error(),
+		})
 	}
 
 	return ctx.Response().Success().Json(map[string]any{
@@ -211,32 +259,70 @@ func (r *ActivityController) Update(ctx http.Context) http.Response {
 	})
 }
 
+// validationFailed responds with the failed rules' messages per field
+func validationFailed(ctx http.Context, errs validation.Errors) http.Response {
+	return ctx.Response().Status(422).Json(map[string]any{
+		"error":  "Validation failed",
+		"errors": errs.All(),
+	})
+}
+
+// History returns the revisions of an activity, oldest first. It stays
+// available after the activity is deleted.
+func (r *ActivityController) History(ctx http.Context) http.Response {
+	id := ctx.Request().Route("id")
+
+	var revisions []models.ActivityRevision
+	if err := facades.Orm().Query().Where("activity_id = ?", id).OrderBy("id").Find(&revisions); err != nil {
+		return ctx.Response().Status(500).Json(map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	// Activities created before history was recorded have no revisions
+	if len(revisions) == 0 {
+		var activity models.Activity
+		if err := facades.Orm().Query().Where("id = ?", id).FirstOrFail(&activity); err != nil {
+			return ctx.Response().Status(404).Json(map[string]any{
+				"error": "Activity not found",
+			})
+		}
+		revisions = []models.ActivityRevision{}
+	}
+
+	return ctx.Response().Success().Json(map[string]any{
+		"data": revisions,
+	})
+}
+
 // Destroy deletes an activity
 func (r *ActivityController) Destroy(ctx http.Context) http.Response {
 	id := ctx.Request().Route("id")
 	var activity models.Activity
 
 	// Find activity before deleting to publish event
-	if err := facades.Orm().Query().Where("id = ?", id).First(&activity).Error; err != nil {
+	if err := facades.Orm().Query().Where("id = ?", id).FirstOrFail(&activity); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Activity not found",
 		})
 	}
 
-	// Delete the activity
-	_, err := facades.Orm().Query().Where("id = ?", id).Delete(&activity)
+	// Delete the activity and record its revision and event atomically
+	err := facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Where("id = ?", id).Delete(&activity); err != nil {
+			return err
+		}
+		if err := services.RecordActivityRevision(tx, activity.ID, models.RevisionDeleted, services.DiffActivity(&activity, nil), claimedActor(ctx)); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "activity.deleted", services.NewActivityPayload(activity), traceContext(ctx))
+	})
 	if err != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": err.Error(),
 		})
 	}
 
-	// Publish event to Kafka
-	if err := r.kafkaService.PublishActivityDeleted(activity); err != nil {
-		facades.Log().Error("Failed to publish activity deleted event: " + err.Error())
-		// Don't return error - the activity was deleted successfully
-	}
-
 	return ctx.Response().Success().Json(map[string]any{
 		"message": "Activity deleted successfully",
 	})
@@ -244,14 +330,49 @@ func (r *ActivityController) Destroy(ctx http.Context) http.Response {
 
 // Health returns the health status of the service
 func (r *ActivityController) Health(ctx http.Context) http.Response {
+	driver := facades.Config().GetString("kafka.driver", services.BrokerDriverKafka)
+	kafka := map[string]any{
+		"driver":  driver,
+		"enabled": false,
+		"profile": facades.Config().GetString("kafka.profile", "local"),
+		"topic":   facades.Config().GetString("kafka.activity_events_topic", "activity-events"),
+	}
+
+	publisher, err := services.Publisher()
+	if err != nil {
+		kafka["error"] = err.Error()
+	} else {
+		kafka["enabled"] = publisher.IsEnabled()
+	}
+
+	// Brokers and topics are probed on the metrics interval, not per request
+	if kafkaService, ok := publisher.(*services.KafkaService); ok && kafkaService.IsEnabled() {
+		report, err := kafkaService.Health()
+		switch {
+		case err != nil:
+			kafka["healthy"] = false
+			kafka["error"] = err.Error()
+		case report != nil:
+			kafka["healthy"] = report.Healthy
+			kafka["brokers"] = report.Brokers
+			kafka["topics"] = report.Topics
+			kafka["checked_at"] = report.CheckedAt
+		}
+	}
+
+	// Producer traffic is this process's; consumer lag comes from the brokers
+	if kafkaService, ok := publisher.(*services.KafkaService); ok {
+		summary, err := kafkaService.MetricsSummary()
+		if err != nil {
+			kafka["metrics_error"] = err.Error()
+		}
+		kafka["metrics"] = summary
+	}
+
 	return ctx.Response().Success().Json(map[string]any{
 		"status":    "healthy",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"service":   "activity-service",
-		"kafka": map[string]any{
-			"enabled": r.kafkaService.IsEnabled(),
-			"profile": facades.Config().GetString("kafka.profile", "local"),
-			"topic":   facades.Config().GetString("kafka.activity_events_topic", "activity-events"),
-		},
+		"kafka":     kafka,
 	})
 }
@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"goravel/app/models"
+	"goravel/app/services"
 	"strconv"
 	"time"
 
+	"github.com/goravel/framework/contracts/database/orm"
 	"github.com/goravel/framework/contracts/http"
 	"github.com/goravel/framework/facades"
 )
@@ -105,7 +107,13 @@ func (r *BaySessionController) Store(ctx http.Context) http.Response {
 		Duration:  request.Duration,
 	}
 
-	if err := facades.Orm().Query().Create(&baySession); err != nil {
+	err := facades.Orm().Transaction(func(tx orm.Query) error {
+		if err := tx.Create(&baySession); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "bay_session.created", services.NewBaySessionPayload(baySession), traceContext(ctx))
+	})
+	if err != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": err.Error(),
 		})
@@ -148,7 +156,7 @@ func (r *BaySessionController) Update(ctx http.Context) http.Response {
 	}
 
 	var baySession models.BaySession
-	if err := facades.Orm().Query().Where("id = ?", id).First(&baySession); err != nil {
+	if err := facades.Orm().Query().Where("id = ?", id).FirstOrFail(&baySession); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Bay session not found",
 		})
@@ -164,30 +172,30 @@ func (r *BaySessionController) Update(ctx http.Context) http.Response {
 		baySession.StartTime = *request.StartTime
 	}
 
-	_, err := facades.Orm().Query().Model(&baySession).Update("visit_id", baySession.VisitID)
-	if err != nil {
-		return ctx.Response().Status(500).Json(map[string]any{
-			"error": err.Error(),
-		})
-	}
+	err := facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Model(&baySession).Update("visit_id", baySession.VisitID); err != nil {
+			return err
+		}
+		if _, err := tx.Model(&baySession).Update("start_time", baySession.StartTime); err != nil {
+			return err
+		}
+		if _, err := tx.Model(&baySession).Update("duration", baySession.Duration); err != nil {
+			return err
+		}
 
-	_, err = facades.Orm().Query().Model(&baySession).Update("start_time", baySession.StartTime)
-	if err != nil {
-		return ctx.Response().Status(500).Json(map[string]any{
-			"error": err.Error(),
-		})
-	}
+		// Refresh to get updated values
+		if err := tx.Where("id = ?", id).First(&baySession); err != nil {
+			return err
+		}
 
-	_, err = facades.Orm().Query().Model(&baySession).Update("duration", baySession.Duration)
+		return services.RecordEvent(tx, "bay_session.updated", services.NewBaySessionPayload(baySession), traceContext(ctx))
+	})
 	if err != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": err.Error(),
 		})
 	}
 
-	// Refresh to get updated values
-	facades.Orm().Query().Where("id = ?", id).First(&baySession)
-
 	return ctx.Response().Success().Json(baySession)
 }
 
@@ -196,13 +204,18 @@ func (r *BaySessionController) Destroy(ctx http.Context) http.Response {
 	id := ctx.Request().Route("id")
 
 	var baySession models.BaySession
-	if err := facades.Orm().Query().Where("id = ?", id).First(&baySession); err != nil {
+	if err := facades.Orm().Query().Where("id = ?", id).FirstOrFail(&baySession); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Bay session not found",
 		})
 	}
 
-	_, err := facades.Orm().Query().Delete(&baySession)
+	err := facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Delete(&baySession); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "bay_session.deleted", services.NewBaySessionPayload(baySession), traceContext(ctx))
+	})
 	if err != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": err.Error(),
@@ -2,8 +2,10 @@ package controllers
 
 import (
 	"goravel/app/models"
+	"goravel/app/services"
 	"strconv"
 
+	"github.com/goravel/framework/contracts/database/orm"
 	"github.com/goravel/framework/contracts/http"
 	"github.com/goravel/framework/facades"
 )
@@ -108,16 +110,20 @@ func (r *BaySessionPlayerController) Store(ctx http.Context) http.Response {
 
 	// Verify bay session exists
 	var baySession models.BaySession
-	if err := facades.Orm().Query().Where("id = ?", baySessionID).First(&baySession); err != nil {
+	if err := facades.Orm().Query().Where("id = ?", baySessionID).FirstOrFail(&baySession); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Bay session not found",
 		})
 	}
 
 	// Check if player already exists in this session
-	var existingPlayer models.BaySessionPlayer
-	checkErr := facades.Orm().Query().Where("bay_session_id = ? AND player_id = ?", baySessionID, request.PlayerID).First(&existingPlayer)
-	if checkErr == nil {
+	exists, checkErr := facades.Orm().Query().Model(&models.BaySessionPlayer{}).Where("bay_session_id = ? AND player_id = ?", baySessionID, request.PlayerID).Exists()
+	if checkErr != nil {
+		return ctx.Response().Status(500).Json(map[string]any{
+			"error": checkErr.Error(),
+		})
+	}
+	if exists {
 		// Player already exists
 		return ctx.Response().Status(409).Json(map[string]any{
 			"error": "Player already exists in this session",
@@ -129,7 +135,13 @@ func (r *BaySessionPlayerController) Store(ctx http.Context) http.Response {
 		PlayerID:     request.PlayerID,
 	}
 
-	if err := facades.Orm().Query().Create(&player); err != nil {
+	err = facades.Orm().Transaction(func(tx orm.Query) error {
+		if err := tx.Create(&player); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "bay_session.player_joined", services.NewBaySessionPlayerPayload(player), traceContext(ctx))
+	})
+	if err != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": err.Error(),
 		})
@@ -150,7 +162,7 @@ func (r *BaySessionPlayerController) Show(ctx http.Context) http.Response {
 	playerID := ctx.Request().Route("player_id")
 
 	var player models.BaySessionPlayer
-	if err := facades.Orm().Query().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).First(&player); err != nil {
+	if err := facades.Orm().Query().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).FirstOrFail(&player); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Player not found in this session",
 		})
@@ -171,13 +183,18 @@ func (r *BaySessionPlayerController) Destroy(ctx http.Context) http.Response {
 	playerID := ctx.Request().Route("player_id")
 
 	var player models.BaySessionPlayer
-	if err := facades.Orm().Query().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).First(&player); err != nil {
+	if err := facades.Orm().Query().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).FirstOrFail(&player); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Player not found in this session",
 		})
 	}
 
-	_, delErr := facades.Orm().Query().Delete(&player)
+	delErr := facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Delete(&player); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "bay_session.player_left", services.NewBaySessionPlayerPayload(player), traceContext(ctx))
+	})
 	if delErr != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": delErr.Error(),
@@ -201,13 +218,18 @@ func (r *BaySessionPlayerController) DeletePermanently(ctx http.Context) http.Re
 	playerID := ctx.Request().Route("player_id")
 
 	var player models.BaySessionPlayer
-	if err := facades.Orm().Query().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).First(&player); err != nil {
+	if err := facades.Orm().Query().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).FirstOrFail(&player); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Player not found in this session",
 		})
 	}
 
-	_, forceErr := facades.Orm().Query().ForceDelete(&player)
+	forceErr := facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.ForceDelete(&player); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "bay_session.player_purged", services.NewBaySessionPlayerPayload(player), traceContext(ctx))
+	})
 	if forceErr != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": forceErr.Error(),
@@ -231,13 +253,24 @@ func (r *BaySessionPlayerController) Restore(ctx http.Context) http.Response {
 	playerID := ctx.Request().Route("player_id")
 
 	var player models.BaySessionPlayer
-	if err := facades.Orm().Query().WithTrashed().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).First(&player); err != nil {
+	if err := facades.Orm().Query().WithTrashed().Where("bay_session_id = ? AND id = ?", baySessionID, playerID).FirstOrFail(&player); err != nil {
 		return ctx.Response().Status(404).Json(map[string]any{
 			"error": "Player not found in this session",
 		})
 	}
+	if !player.DeletedAt.Valid {
+		// Nothing to restore, so no player_restored event either
+		return ctx.Response().Status(409).Json(map[string]any{
+			"error": "Player is not deleted",
+		})
+	}
 
-	_, restoreErr := facades.Orm().Query().Restore(&player)
+	restoreErr := facades.Orm().Transaction(func(tx orm.Query) error {
+		if _, err := tx.Restore(&player); err != nil {
+			return err
+		}
+		return services.RecordEvent(tx, "bay_session.player_restored", services.NewBaySessionPlayerPayload(player), traceContext(ctx))
+	})
 	if restoreErr != nil {
 		return ctx.Response().Status(500).Json(map[string]any{
 			"error": restoreErr.Error(),
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"github.com/goravel/framework/contracts/http"
+
+	"goravel/app/services"
+)
+
+// traceContext returns the caller-supplied correlation ID and W3C trace context
+// for the request, if any
+func traceContext(ctx http.Context) services.TraceContext {
+	trace := services.TraceContext{
+		CorrelationID: ctx.Request().Header("X-Correlation-ID"),
+		TraceParent:   ctx.Request().Header("traceparent"),
+	}
+	if trace.CorrelationID == "" {
+		trace.CorrelationID = ctx.Request().Header("X-Request-ID")
+	}
+	return trace
+}
+
+// claimedActor returns who the caller says made the request, from X-Actor-ID
+// (falling back to X-User-ID), or "" if not given. The API has no
+// authentication yet, so the value is caller-asserted and not verified; it must
+// not be treated as the authenticated author of a change.
+func claimedActor(ctx http.Context) string {
+	if actor := ctx.Request().Header("X-Actor-ID"); actor != "" {
+		return actor
+	}
+	return ctx.Request().Header("X-User-ID")
+}
@@ -0,0 +1,152 @@
+package requests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goravel/framework/contracts/http"
+	"github.com/goravel/framework/contracts/validation"
+	"github.com/goravel/framework/facades"
+
+	"goravel/app/models"
+)
+
+// ActivityStatuses are the statuses an activity can have
+var ActivityStatuses = []string{"active", "inactive", "completed", "cancelled"}
+
+// MaxMetadataBytes limits the JSON-encoded size of an activity's metadata
+const MaxMetadataBytes = 16384
+
+// UpdateActivityRequest is a partial update of an activity. Only the fields
+// below can be changed; other keys in the body are ignored. Fields missing
+// from the body keep their value, and null clears description, metadata,
+// started_at and completed_at.
+type UpdateActivityRequest struct {
+	Name        string         `form:"name" json:"name"`
+	Description string         `form:"description" json:"description"`
+	Type        string         `form:"type" json:"type"`
+	Status      string         `form:"status" json:"status"`
+	Metadata    map[string]any `form:"metadata" json:"metadata"`
+	StartedAt   string         `form:"started_at" json:"started_at"`
+	CompletedAt string         `form:"completed_at" json:"completed_at"`
+}
+
+func (r *UpdateActivityRequest) Authorize(ctx http.Context) error {
+	return nil
+}
+
+func (r *UpdateActivityRequest) Rules(ctx http.Context) map[string]string {
+	rules := map[string]string{
+		"name":         "string|max_len:255",
+		"description":  "string|max_len:65535",
+		"type":         "string|max_len:255",
+		"status":       "in:" + strings.Join(ActivityStatuses, ","),
+		"metadata":     "map|max_json_bytes:" + strconv.Itoa(MaxMetadataBytes),
+		"started_at":   "rfc3339",
+		"completed_at": "rfc3339|not_before:started_at",
+	}
+
+	// Name, type and status can be changed but not cleared
+	input := ctx.Request().All()
+	for _, field := range []string{"name", "type", "status"} {
+		if _, exist := input[field]; exist {
+			rules[field] = "required|" + rules[field]
+		}
+	}
+
+	return rules
+}
+
+// Apply sets the fields present in the request on activity and returns
+// their columns with the new values. It fails if a timestamp cannot be parsed,
+// leaving activity partly updated.
+func (r *UpdateActivityRequest) Apply(ctx http.Context, activity *models.Activity) (map[string]any, error) {
+	input := ctx.Request().All()
+	present := func(field string) bool {
+		_, exist := input[field]
+		return exist
+	}
+
+	if present("name") {
+		activity.Name = r.Name
+	}
+	if present("description") {
+		activity.Description = r.Description
+	}
+	if present("type") {
+		activity.Type = r.Type
+	}
+	if present("status") {
+		activity.Status = r.Status
+	}
+	if present("metadata") {
+		activity.Metadata = nil
+		if r.Metadata != nil {
+			activity.Metadata = models.JSONMap(r.Metadata)
+		}
+	}
+	if present("started_at") {
+		startedAt, err := parseTime(r.StartedAt)
+		if err != nil {
+			return nil, fmt.Errorf("started_at: %w", err)
+		}
+		activity.StartedAt = startedAt
+	}
+	if present("completed_at") {
+		completedAt, err := parseTime(r.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("completed_at: %w", err)
+		}
+		activity.CompletedAt = completedAt
+	}
+
+	columns := map[string]any{
+		"name":         activity.Name,
+		"description":  activity.Description,
+		"type":         activity.Type,
+		"status":       activity.Status,
+		"metadata":     activity.Metadata,
+		"started_at":   activity.StartedAt,
+		"completed_at": activity.CompletedAt,
+	}
+	values := map[string]any{}
+	for column, value := range columns {
+		if present(column) {
+			values[column] = value
+		}
+	}
+	return values, nil
+}
+
+// ValidateActivity checks the rules spanning several fields against an
+// activity with the request applied, as the request may change only one of them
+func (r *UpdateActivityRequest) ValidateActivity(activity *models.Activity) (validation.Errors, error) {
+	if activity.StartedAt == nil || activity.CompletedAt == nil {
+		return nil, nil
+	}
+
+	validator, err := facades.Validation().Make(map[string]any{
+		"started_at":   activity.StartedAt.Format(time.RFC3339Nano),
+		"completed_at": activity.CompletedAt.Format(time.RFC3339Nano),
+	}, map[string]string{
+		"completed_at": "not_before:started_at",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return validator.Errors(), nil
+}
+
+// parseTime parses a timestamp that passed the rfc3339 rule; empty means unset
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -20,22 +20,22 @@ func (job *ActivityCreatedJob) Handle(args ...any) error {
 		"name":        job.Data["name"],
 	})
 
-	// Publish activity created event to Kafka
-	kafkaService := services.GetKafkaService()
-	if kafkaService.IsEnabled() {
-		if err := kafkaService.PublishActivityCreated(job.Data); err != nil {
-			facades.Log().Error("Failed to publish activity created event", map[string]interface{}{
-				"error":       err.Error(),
-				"activity_id": job.Data["id"],
-			})
-			return err
-		}
-		facades.Log().Info("Activity created event published to Kafka", map[string]interface{}{
+	// Publish activity created event to the configured broker; Kafka buffers it in the outbox while down
+	publisher, err := services.Publisher()
+	if err != nil {
+		facades.Log().Error("Failed to resolve the event broker: " + err.Error())
+		return err
+	}
+	if err := publisher.PublishEvent("activity.created", job.Data); err != nil {
+		facades.Log().Error("Failed to publish activity created event", map[string]interface{}{
+			"error":       err.Error(),
 			"activity_id": job.Data["id"],
 		})
-	} else {
-		facades.Log().Warning("Kafka service is disabled, event not published")
+		return err
 	}
+	facades.Log().Info("Activity created event published", map[string]interface{}{
+		"activity_id": job.Data["id"],
+	})
 
 	return nil
 }
@@ -19,22 +19,22 @@ func (job *ActivityDeletedJob) Handle(args ...any) error {
 		"activity_id": job.Data["id"],
 	})
 
-	// Publish activity deleted event to Kafka
-	kafkaService := services.GetKafkaService()
-	if kafkaService.IsEnabled() {
-		if err := kafkaService.PublishActivityDeleted(job.Data); err != nil {
-			facades.Log().Error("Failed to publish activity deleted event", map[string]interface{}{
-				"error":       err.Error(),
-				"activity_id": job.Data["id"],
-			})
-			return err
-		}
-		facades.Log().Info("Activity deleted event published to Kafka", map[string]interface{}{
+	// Publish activity deleted event to the configured broker; Kafka buffers it in the outbox while down
+	publisher, err := services.Publisher()
+	if err != nil {
+		facades.Log().Error("Failed to resolve the event broker: " + err.Error())
+		return err
+	}
+	if err := publisher.PublishEvent("activity.deleted", job.Data); err != nil {
+		facades.Log().Error("Failed to publish activity deleted event", map[string]interface{}{
+			"error":       err.Error(),
 			"activity_id": job.Data["id"],
 		})
-	} else {
-		facades.Log().Warning("Kafka service is disabled, event not published")
+		return err
 	}
+	facades.Log().Info("Activity deleted event published", map[string]interface{}{
+		"activity_id": job.Data["id"],
+	})
 
 	return nil
 }
@@ -20,22 +20,22 @@ func (job *ActivityUpdatedJob) Handle(args ...any) error {
 		"name":        job.Data["name"],
 	})
 
-	// Publish activity updated event to Kafka
-	kafkaService := services.GetKafkaService()
-	if kafkaService.IsEnabled() {
-		if err := kafkaService.PublishActivityUpdated(job.Data); err != nil {
-			facades.Log().Error("Failed to publish activity updated event", map[string]interface{}{
-				"error":       err.Error(),
-				"activity_id": job.Data["id"],
-			})
-			return err
-		}
-		facades.Log().Info("Activity updated event published to Kafka", map[string]interface{}{
+	// Publish activity updated event to the configured broker; Kafka buffers it in the outbox while down
+	publisher, err := services.Publisher()
+	if err != nil {
+		facades.Log().Error("Failed to resolve the event broker: " + err.Error())
+		return err
+	}
+	if err := publisher.PublishEvent("activity.updated", job.Data); err != nil {
+		facades.Log().Error("Failed to publish activity updated event", map[string]interface{}{
+			"error":       err.Error(),
 			"activity_id": job.Data["id"],
 		})
-	} else {
-		facades.Log().Warning("Kafka service is disabled, event not published")
+		return err
 	}
+	facades.Log().Info("Activity updated event published", map[string]interface{}{
+		"activity_id": job.Data["id"],
+	})
 
 	return nil
 }
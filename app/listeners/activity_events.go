@@ -0,0 +1,57 @@
+package listeners
+
+import (
+	"context"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/facades"
+)
+
+// HandleActivityCreated processes activity created events
+func HandleActivityCreated(ctx context.Context, event *services.Event) error {
+	var activity services.ActivityPayload
+	if err := event.DecodeData(&activity); err != nil {
+		return err
+	}
+
+	facades.Log().Info("Handling activity created event", map[string]interface{}{
+		"activity_id":   activity.ID,
+		"activity_name": activity.Name,
+	})
+	// Add custom business logic here
+	// - Update search indices
+	// - Send notifications
+	// - Update analytics
+	// - Sync with external systems
+	return nil
+}
+
+// HandleActivityUpdated processes activity updated events
+func HandleActivityUpdated(ctx context.Context, event *services.Event) error {
+	var activity services.ActivityPayload
+	if err := event.DecodeData(&activity); err != nil {
+		return err
+	}
+
+	facades.Log().Info("Handling activity updated event", map[string]interface{}{
+		"activity_id":   activity.ID,
+		"activity_name": activity.Name,
+	})
+	// Add custom business logic here
+	return nil
+}
+
+// HandleActivityDeleted processes activity deleted events
+func HandleActivityDeleted(ctx context.Context, event *services.Event) error {
+	var activity services.ActivityPayload
+	if err := event.DecodeData(&activity); err != nil {
+		return err
+	}
+
+	facades.Log().Info("Handling activity deleted event", map[string]interface{}{
+		"activity_id": activity.ID,
+	})
+	// Add custom business logic here
+	return nil
+}
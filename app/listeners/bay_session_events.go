@@ -0,0 +1,42 @@
+package listeners
+
+import (
+	"context"
+
+	"goravel/app/services"
+
+	"github.com/goravel/framework/facades"
+)
+
+// HandleBaySessionChanged processes bay session created, updated and deleted events
+func HandleBaySessionChanged(ctx context.Context, event *services.Event) error {
+	var baySession services.BaySessionPayload
+	if err := event.DecodeData(&baySession); err != nil {
+		return err
+	}
+
+	facades.Log().Info("Handling bay session event", map[string]interface{}{
+		"event_type":     event.EventType,
+		"bay_session_id": baySession.ID,
+		"visit_id":       baySession.VisitID,
+	})
+	// Add custom business logic here
+	return nil
+}
+
+// HandleBaySessionPlayerChanged processes bay session player joined, left,
+// purged and restored events
+func HandleBaySessionPlayerChanged(ctx context.Context, event *services.Event) error {
+	var player services.BaySessionPlayerPayload
+	if err := event.DecodeData(&player); err != nil {
+		return err
+	}
+
+	facades.Log().Info("Handling bay session player event", map[string]interface{}{
+		"event_type":     event.EventType,
+		"bay_session_id": player.BaySessionID,
+		"player_id":      player.PlayerID,
+	})
+	// Add custom business logic here
+	return nil
+}
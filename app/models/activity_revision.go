@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Activity revision actions
+const (
+	RevisionCreated = "created"
+	RevisionUpdated = "updated"
+	RevisionDeleted = "deleted"
+)
+
+// FieldChange is the value of a field before and after a change. Old is nil
+// for created activities and New is nil for deleted ones.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// FieldChanges maps field names to their changes and is stored as JSON
+type FieldChanges map[string]FieldChange
+
+// Value implements the driver.Valuer interface
+func (c FieldChanges) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface
+func (c *FieldChanges) Scan(value interface{}) error {
+	if value == nil {
+		*c = FieldChanges{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return nil
+	}
+
+	return json.Unmarshal(bytes, c)
+}
+
+// ActivityRevision records one create, update or delete of an activity.
+// ClaimedActor is who the caller reported in X-Actor-ID or X-User-ID; it is
+// not authenticated.
+type ActivityRevision struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	ActivityID   uint         `json:"activity_id"`
+	Action       string       `json:"action"`
+	Changes      FieldChanges `json:"changes" gorm:"type:json"`
+	ClaimedActor string       `json:"claimed_actor"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// TableName specifies the table name for the ActivityRevision model
+func (r *ActivityRevision) TableName() string {
+	return "activity_revisions"
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+)
+
+// OutboxEvent is an event waiting to be relayed to Kafka. Rows are written in
+// the same transaction as the change they describe.
+type OutboxEvent struct {
+	orm.Model
+	EventID     string     `json:"event_id"`
+	EventType   string     `json:"event_type"`
+	Payload     string     `json:"payload"`
+	Status      string     `json:"status"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error"`
+	AvailableAt time.Time  `json:"available_at"`
+	SentAt      *time.Time `json:"sent_at"`
+	LockedUntil *time.Time `json:"locked_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the OutboxEvent model
+func (o *OutboxEvent) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// ProcessedEvent records an event ID that has already been handled by a
+// consumer, so redelivered messages can be skipped
+type ProcessedEvent struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	EventID     string    `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// TableName specifies the table name for the ProcessedEvent model
+func (p *ProcessedEvent) TableName() string {
+	return "processed_events"
+}
@@ -1,9 +1,14 @@
 package providers
 
 import (
+	"time"
+
 	"github.com/goravel/framework/contracts/event"
 	"github.com/goravel/framework/contracts/foundation"
 	"github.com/goravel/framework/facades"
+
+	"goravel/app/listeners"
+	"goravel/app/services"
 )
 
 type EventServiceProvider struct {
@@ -11,6 +16,7 @@ type EventServiceProvider struct {
 
 func (receiver *EventServiceProvider) Register(app foundation.Application) {
 	facades.Event().Register(receiver.listen())
+	receiver.registerKafkaHandlers(services.EventHandlers())
 }
 
 func (receiver *EventServiceProvider) Boot(app foundation.Application) {
@@ -29,3 +35,26 @@ func (receiver *EventServiceProvider) listen() map[event.Event][]event.Listener
 		// },
 	}
 }
+
+// registerKafkaHandlers registers the handlers run by kafka:consume-activities.
+// Several handlers may be registered per event type, or per pattern such as "activity.*".
+func (receiver *EventServiceProvider) registerKafkaHandlers(registry *services.EventHandlerRegistry) {
+	registry.Use(
+		services.RecoveryMiddleware(),
+		services.LoggingMiddleware(),
+		services.TimingMiddleware(time.Second),
+	)
+
+	registry.Register("activity.created", listeners.HandleActivityCreated)
+	registry.Register("activity.updated", listeners.HandleActivityUpdated)
+	registry.Register("activity.deleted", listeners.HandleActivityDeleted)
+
+	registry.Register("bay_session.created", listeners.HandleBaySessionChanged)
+	registry.Register("bay_session.updated", listeners.HandleBaySessionChanged)
+	registry.Register("bay_session.deleted", listeners.HandleBaySessionChanged)
+
+	registry.Register("bay_session.player_joined", listeners.HandleBaySessionPlayerChanged)
+	registry.Register("bay_session.player_left", listeners.HandleBaySessionPlayerChanged)
+	registry.Register("bay_session.player_purged", listeners.HandleBaySessionPlayerChanged)
+	registry.Register("bay_session.player_restored", listeners.HandleBaySessionPlayerChanged)
+}
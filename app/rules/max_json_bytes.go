@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/goravel/framework/contracts/validation"
+)
+
+// MaxJsonBytes limits the JSON-encoded size of a value, e.g. "max_json_bytes:16384"
+type MaxJsonBytes struct {
+}
+
+// Signature The name of the rule.
+func (receiver *MaxJsonBytes) Signature() string {
+	return "max_json_bytes"
+}
+
+// Passes Determine if the validation rule passes.
+func (receiver *MaxJsonBytes) Passes(data validation.Data, val any, options ...any) bool {
+	if len(options) == 0 {
+		return false
+	}
+	limit, err := strconv.Atoi(fmt.Sprint(options[0]))
+	if err != nil {
+		return false
+	}
+
+	encoded, err := json.Marshal(val)
+	return err == nil && len(encoded) <= limit
+}
+
+// Message Get the validation error message.
+func (receiver *MaxJsonBytes) Message() string {
+	return "The :attribute may not be larger than {args0} bytes when encoded as JSON."
+}
@@ -0,0 +1,47 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goravel/framework/contracts/validation"
+)
+
+// NotBefore requires an RFC 3339 timestamp that is not before the timestamp
+// in another field, e.g. "not_before:started_at". It passes when the other
+// field is missing or empty, or either value is not a timestamp; format is
+// checked by the rfc3339 rule.
+type NotBefore struct {
+}
+
+// Signature The name of the rule.
+func (receiver *NotBefore) Signature() string {
+	return "not_before"
+}
+
+// Passes Determine if the validation rule passes.
+func (receiver *NotBefore) Passes(data validation.Data, val any, options ...any) bool {
+	if len(options) == 0 {
+		return false
+	}
+
+	other, exist := data.Get(fmt.Sprint(options[0]))
+	if !exist || other == nil || other == "" {
+		return true
+	}
+
+	value, err := time.Parse(time.RFC3339, fmt.Sprint(val))
+	if err != nil {
+		return true
+	}
+	otherValue, err := time.Parse(time.RFC3339, fmt.Sprint(other))
+	if err != nil {
+		return true
+	}
+	return !value.Before(otherValue)
+}
+
+// Message Get the validation error message.
+func (receiver *NotBefore) Message() string {
+	return "The :attribute must not be before {args0}."
+}
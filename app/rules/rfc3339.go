@@ -0,0 +1,31 @@
+package rules
+
+import (
+	"time"
+
+	"github.com/goravel/framework/contracts/validation"
+)
+
+// Rfc3339 requires a timestamp in RFC 3339 format, e.g. 2026-10-17T09:00:00Z
+type Rfc3339 struct {
+}
+
+// Signature The name of the rule.
+func (receiver *Rfc3339) Signature() string {
+	return "rfc3339"
+}
+
+// Passes Determine if the validation rule passes.
+func (receiver *Rfc3339) Passes(data validation.Data, val any, options ...any) bool {
+	value, ok := val.(string)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
+
+// Message Get the validation error message.
+func (receiver *Rfc3339) Message() string {
+	return "The :attribute must be an RFC 3339 timestamp, e.g. 2026-10-17T09:00:00Z."
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/contracts/database/orm"
+)
+
+// activityFields returns the fields of an activity kept in its history, keyed
+// by their JSON names. Times are normalised to UTC strings and empty metadata
+// to nil so values read back from the database compare equal.
+func activityFields(activity *models.Activity) map[string]interface{} {
+	timestamp := func(t *time.Time) interface{} {
+		if t == nil {
+			return nil
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	var metadata interface{}
+	if len(activity.Metadata) > 0 {
+		metadata = map[string]interface{}(activity.Metadata)
+	}
+
+	return map[string]interface{}{
+		"name":         activity.Name,
+		"description":  activity.Description,
+		"type":         activity.Type,
+		"metadata":     metadata,
+		"status":       activity.Status,
+		"started_at":   timestamp(activity.StartedAt),
+		"completed_at": timestamp(activity.CompletedAt),
+	}
+}
+
+// DiffActivity returns the fields that differ between two versions of an
+// activity. A nil before lists every set field of a new activity; a nil after
+// lists every set field of a deleted one.
+func DiffActivity(before, after *models.Activity) models.FieldChanges {
+	var old, updated map[string]interface{}
+	if before != nil {
+		old = activityFields(before)
+	}
+	if after != nil {
+		updated = activityFields(after)
+	}
+
+	changes := models.FieldChanges{}
+	for _, fields := range []map[string]interface{}{old, updated} {
+		for field := range fields {
+			if _, seen := changes[field]; seen || sameValue(old[field], updated[field]) {
+				continue
+			}
+			changes[field] = models.FieldChange{Old: old[field], New: updated[field]}
+		}
+	}
+	return changes
+}
+
+// sameValue compares two field values by their JSON encoding. An empty string
+// is treated as unset, so a new activity without a description lists no change.
+func sameValue(a, b interface{}) bool {
+	if a == "" {
+		a = nil
+	}
+	if b == "" {
+		b = nil
+	}
+	encodedA, _ := json.Marshal(a)
+	encodedB, _ := json.Marshal(b)
+	return bytes.Equal(encodedA, encodedB)
+}
+
+// RecordActivityRevision stores a revision of an activity. The query should be
+// the transaction that writes the change, like RecordEvent. claimedActor is
+// whoever the caller says made the change; it is stored as given.
+func RecordActivityRevision(tx orm.Query, activityID uint, action string, changes models.FieldChanges, claimedActor string) error {
+	return tx.Create(&models.ActivityRevision{
+		ActivityID:   activityID,
+		Action:       action,
+		Changes:      changes,
+		ClaimedActor: claimedActor,
+		CreatedAt:    time.Now().UTC(),
+	})
+}
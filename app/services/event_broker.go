@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Broker drivers selectable with kafka.driver
+const (
+	BrokerDriverKafka  = "kafka"
+	BrokerDriverMemory = "memory"
+	BrokerDriverLog    = "log"
+)
+
+// EventPublisher publishes events
+type EventPublisher interface {
+	// Publish delivers an envelope as is, so retries keep its event ID. It
+	// returns ErrKafkaDisabled while the broker is unavailable.
+	Publish(ctx context.Context, envelope *EventEnvelope) error
+	// PublishEvent wraps data in a new envelope and publishes it
+	PublishEvent(eventType string, data interface{}) error
+	IsEnabled() bool
+}
+
+// EventConsumer delivers events to the handlers registered in EventHandlers()
+type EventConsumer interface {
+	// ConsumerTopics returns the topics consumed by default
+	ConsumerTopics() []string
+	// WaitForConnection blocks until the broker can be consumed from or ctx is cancelled
+	WaitForConnection(ctx context.Context) error
+	// Consume dispatches the events on topics until ctx is cancelled
+	Consume(ctx context.Context, topics []string) error
+	Close() error
+}
+
+// EventBroker publishes and consumes events
+type EventBroker interface {
+	EventPublisher
+	EventConsumer
+}
+
+var (
+	_ EventBroker = (*KafkaService)(nil)
+	_ EventBroker = (*MemoryBroker)(nil)
+	_ EventBroker = (*LogBroker)(nil)
+)
+
+var (
+	eventBroker   EventBroker
+	eventBrokerMu sync.Mutex
+)
+
+// Broker returns the broker selected by kafka.driver: the Kafka service, an
+// in-memory broker or one that only logs events. An unknown driver is an
+// error rather than a fallback, so a typo never connects to a real cluster.
+func Broker() (EventBroker, error) {
+	eventBrokerMu.Lock()
+	defer eventBrokerMu.Unlock()
+
+	if eventBroker == nil {
+		broker, err := NewBroker(facades.Config().GetString("kafka.driver", BrokerDriverKafka))
+		if err != nil {
+			return nil, err
+		}
+		eventBroker = broker
+	}
+	return eventBroker, nil
+}
+
+// Publisher returns the broker events are published to
+func Publisher() (EventPublisher, error) {
+	return Broker()
+}
+
+// Consumer returns the broker events are consumed from
+func Consumer() (EventConsumer, error) {
+	return Broker()
+}
+
+// SetBroker replaces the broker returned by Broker, e.g. with a fresh
+// MemoryBroker in a test. With nil the next call reads kafka.driver again.
+func SetBroker(broker EventBroker) {
+	eventBrokerMu.Lock()
+	defer eventBrokerMu.Unlock()
+
+	eventBroker = broker
+}
+
+// CloseBroker closes the broker on shutdown if one was created, stopping any
+// reconnect in progress
+func CloseBroker() error {
+	eventBrokerMu.Lock()
+	defer eventBrokerMu.Unlock()
+
+	if eventBroker == nil {
+		return nil
+	}
+	err := eventBroker.Close()
+	eventBroker = nil
+	return err
+}
+
+// NewBroker creates the broker for a driver
+func NewBroker(driver string) (EventBroker, error) {
+	switch driver {
+	case BrokerDriverKafka:
+		return GetKafkaService(), nil
+	case BrokerDriverMemory:
+		return NewMemoryBroker(), nil
+	case BrokerDriverLog:
+		return NewLogBroker(), nil
+	default:
+		return nil, fmt.Errorf("unsupported kafka.driver %q (expected %s, %s or %s)",
+			driver, BrokerDriverKafka, BrokerDriverMemory, BrokerDriverLog)
+	}
+}
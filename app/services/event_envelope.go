@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/goravel/framework/facades"
+)
+
+// CurrentSchemaVersion is the envelope version written by this service.
+//
+// Version history:
+//   - 1: {event_type, event_id, timestamp, data}, where event_id was the app name
+//   - 2: adds schema_version, source, occurred_at, correlation_id and subject;
+//     traceparent was added later as an optional field
+const CurrentSchemaVersion = 2
+
+// ErrUnsupportedSchemaVersion is returned for envelopes newer than this service understands
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+
+// EventEnvelope wraps every event published to Kafka
+type EventEnvelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	EventID       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Source        string          `json:"source"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	TraceParent   string          `json:"traceparent,omitempty"`
+	Subject       string          `json:"subject,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// EventPayload is implemented by typed payloads that identify the aggregate they describe
+type EventPayload interface {
+	EventSubject() string
+}
+
+// NewEventEnvelope builds a current-version envelope around the given payload
+func NewEventEnvelope(eventType string, payload interface{}, correlationID string) (*EventEnvelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &EventEnvelope{
+		SchemaVersion: CurrentSchemaVersion,
+		EventID:       uuid.NewString(),
+		EventType:     eventType,
+		Source:        facades.Config().GetString("kafka.client_id", "activity-service"),
+		OccurredAt:    time.Now().UTC(),
+		CorrelationID: correlationID,
+		Data:          data,
+	}
+	if p, ok := payload.(EventPayload); ok {
+		envelope.Subject = p.EventSubject()
+	}
+
+	return envelope, nil
+}
+
+// legacyEnvelope holds the fields that only exist in version 1 envelopes
+type legacyEnvelope struct {
+	EventEnvelope
+	Timestamp string `json:"timestamp"`
+}
+
+// DecodeEnvelope parses a message value into an envelope, upgrading older
+// schema versions to the current shape
+func DecodeEnvelope(value []byte) (*EventEnvelope, error) {
+	var raw legacyEnvelope
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, err
+	}
+
+	envelope := raw.EventEnvelope
+	switch {
+	case envelope.SchemaVersion == 0:
+		// Version 1 envelopes carry no schema_version
+		envelope.SchemaVersion = 1
+		if t, err := time.Parse(time.RFC3339, raw.Timestamp); err == nil {
+			envelope.OccurredAt = t
+		}
+	case envelope.SchemaVersion > CurrentSchemaVersion:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, envelope.SchemaVersion)
+	}
+
+	if envelope.EventType == "" {
+		return nil, errors.New("event envelope has no event_type")
+	}
+
+	return &envelope, nil
+}
+
+// DecodeData unmarshals the envelope data into a typed payload
+func (e *EventEnvelope) DecodeData(payload interface{}) error {
+	if len(e.Data) == 0 {
+		return errors.New("event envelope has no data")
+	}
+	return json.Unmarshal(e.Data, payload)
+}
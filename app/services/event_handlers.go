@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// Event is a decoded Kafka event passed to registered handlers
+type Event struct {
+	*EventEnvelope
+	Message *kafka.Message
+}
+
+// Header returns the value of a message header, or "" if it is not set
+func (e *Event) Header(key string) string {
+	if e.Message == nil {
+		return ""
+	}
+	return headerValue(e.Message.Headers, key)
+}
+
+// Headers returns all message headers as a map
+func (e *Event) Headers() map[string]string {
+	if e.Message == nil {
+		return map[string]string{}
+	}
+	return MessageHeaders(e.Message)
+}
+
+// EventHandler handles a single event. Events are delivered at least once and
+// a failure in another handler for the same event redelivers it, so handlers
+// must be idempotent.
+type EventHandler func(ctx context.Context, event *Event) error
+
+// EventMiddleware wraps an EventHandler, e.g. for logging or panic recovery
+type EventMiddleware func(next EventHandler) EventHandler
+
+// EventHandlerRegistry maps event types to handlers. Patterns may be an exact
+// event type, a prefix wildcard such as "bay_session.*", or "*" for all events.
+type EventHandlerRegistry struct {
+	mu         sync.RWMutex
+	handlers   map[string][]EventHandler
+	patterns   []string
+	middleware []EventMiddleware
+}
+
+var eventHandlers = NewEventHandlerRegistry()
+
+// EventHandlers returns the registry used by the Kafka consumer
+func EventHandlers() *EventHandlerRegistry {
+	return eventHandlers
+}
+
+// NewEventHandlerRegistry creates an empty registry
+func NewEventHandlerRegistry() *EventHandlerRegistry {
+	return &EventHandlerRegistry{
+		handlers: map[string][]EventHandler{},
+	}
+}
+
+// Register adds handlers for an event type pattern
+func (r *EventHandlerRegistry) Register(pattern string, handlers ...EventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.handlers[pattern]; !ok {
+		r.patterns = append(r.patterns, pattern)
+	}
+	r.handlers[pattern] = append(r.handlers[pattern], handlers...)
+}
+
+// Use adds middleware applied to every handler. The first middleware added is the outermost.
+func (r *EventHandlerRegistry) Use(middleware ...EventMiddleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Handlers returns the handlers registered for an event type, in registration order
+func (r *EventHandlerRegistry) Handlers(eventType string) []EventHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var handlers []EventHandler
+	for _, pattern := range r.patterns {
+		if matchesEventType(pattern, eventType) {
+			handlers = append(handlers, r.handlers[pattern]...)
+		}
+	}
+	return handlers
+}
+
+// Dispatch runs every handler registered for the event. All handlers run even
+// if one fails; their errors are joined so the message is retried as a whole.
+// A retry runs the handlers that already succeeded again, and the event is only
+// marked processed once every handler succeeds, so handlers must be idempotent.
+func (r *EventHandlerRegistry) Dispatch(ctx context.Context, event *Event) error {
+	handlers := r.Handlers(event.EventType)
+	if len(handlers) == 0 {
+		facades.Log().Warning("No handlers registered for event type: " + event.EventType)
+		return nil
+	}
+
+	r.mu.RLock()
+	middleware := r.middleware
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			handler = middleware[i](handler)
+		}
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matchesEventType reports whether an event type matches a registration pattern
+func matchesEventType(pattern string, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(eventType, prefix)
+	}
+	return false
+}
+
+// RecoveryMiddleware turns handler panics into errors so one bad handler
+// cannot crash the consumer
+func RecoveryMiddleware() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, event *Event) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					facades.Log().Error("Event handler panicked", map[string]interface{}{
+						"event_type": event.EventType,
+						"event_id":   event.EventID,
+						"panic":      fmt.Sprint(recovered),
+						"stack":      string(debug.Stack()),
+					})
+					err = fmt.Errorf("event handler panicked: %v", recovered)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+// LoggingMiddleware logs each handled event and any handler error
+func LoggingMiddleware() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, event *Event) error {
+			fields := map[string]interface{}{
+				"event_type": event.EventType,
+				"event_id":   event.EventID,
+				"subject":    event.Subject,
+			}
+			if err := next(ctx, event); err != nil {
+				fields["error"] = err.Error()
+				facades.Log().Error("Event handler failed", fields)
+				return err
+			}
+			facades.Log().Debug("Event handled", fields)
+			return nil
+		}
+	}
+}
+
+// TimingMiddleware logs handlers that take longer than the given threshold
+func TimingMiddleware(threshold time.Duration) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, event *Event) error {
+			start := time.Now()
+			err := next(ctx, event)
+			if elapsed := time.Since(start); elapsed >= threshold {
+				facades.Log().Warning("Slow event handler", map[string]interface{}{
+					"event_type": event.EventType,
+					"event_id":   event.EventID,
+					"duration":   elapsed.String(),
+				})
+			}
+			return err
+		}
+	}
+}
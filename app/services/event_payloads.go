@@ -0,0 +1,108 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"goravel/app/models"
+)
+
+// ActivityPayload is the data carried by activity.* events
+type ActivityPayload struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        string                 `json:"type"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Status      string                 `json:"status"`
+	StartedAt   *time.Time             `json:"started_at"`
+	CompletedAt *time.Time             `json:"completed_at"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// NewActivityPayload builds an event payload from an activity model
+func NewActivityPayload(activity models.Activity) ActivityPayload {
+	return ActivityPayload{
+		ID:          activity.ID,
+		Name:        activity.Name,
+		Description: activity.Description,
+		Type:        activity.Type,
+		Metadata:    activity.Metadata,
+		Status:      activity.Status,
+		StartedAt:   activity.StartedAt,
+		CompletedAt: activity.CompletedAt,
+		CreatedAt:   activity.CreatedAt,
+		UpdatedAt:   activity.UpdatedAt,
+	}
+}
+
+// EventSubject identifies the activity the event describes
+func (p ActivityPayload) EventSubject() string {
+	return "activities/" + strconv.FormatUint(uint64(p.ID), 10)
+}
+
+// ActivityUpdatedPayload is the data carried by activity.updated events: the
+// new state plus the fields that changed, with their old and new values
+type ActivityUpdatedPayload struct {
+	ActivityPayload
+	Changes models.FieldChanges `json:"changes"`
+}
+
+// NewActivityUpdatedPayload builds an activity.updated payload from the updated activity and its changes
+func NewActivityUpdatedPayload(activity models.Activity, changes models.FieldChanges) ActivityUpdatedPayload {
+	return ActivityUpdatedPayload{ActivityPayload: NewActivityPayload(activity), Changes: changes}
+}
+
+// BaySessionPayload is the data carried by bay_session.* lifecycle events
+type BaySessionPayload struct {
+	ID        uint      `json:"id"`
+	VisitID   string    `json:"visit_id"`
+	StartTime time.Time `json:"start_time"`
+	Duration  int       `json:"duration"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewBaySessionPayload builds an event payload from a bay session model
+func NewBaySessionPayload(baySession models.BaySession) BaySessionPayload {
+	return BaySessionPayload{
+		ID:        baySession.ID,
+		VisitID:   baySession.VisitID,
+		StartTime: baySession.StartTime,
+		Duration:  baySession.Duration,
+		CreatedAt: baySession.CreatedAt,
+		UpdatedAt: baySession.UpdatedAt,
+	}
+}
+
+// EventSubject identifies the bay session the event describes
+func (p BaySessionPayload) EventSubject() string {
+	return "bay-sessions/" + strconv.FormatUint(uint64(p.ID), 10)
+}
+
+// BaySessionPlayerPayload is the data carried by bay_session.player_* events
+type BaySessionPlayerPayload struct {
+	ID           uint      `json:"id"`
+	BaySessionID uint64    `json:"bay_session_id"`
+	PlayerID     string    `json:"player_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewBaySessionPlayerPayload builds an event payload from a bay session player model
+func NewBaySessionPlayerPayload(player models.BaySessionPlayer) BaySessionPlayerPayload {
+	return BaySessionPlayerPayload{
+		ID:           player.ID,
+		BaySessionID: player.BaySessionID,
+		PlayerID:     player.PlayerID,
+		CreatedAt:    player.CreatedAt,
+		UpdatedAt:    player.UpdatedAt,
+	}
+}
+
+// EventSubject identifies the bay session the player belongs to, so player
+// events are ordered with the rest of the session's events
+func (p BaySessionPlayerPayload) EventSubject() string {
+	return "bay-sessions/" + strconv.FormatUint(p.BaySessionID, 10)
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// TopicSpec is the desired layout of a topic this service depends on
+type TopicSpec struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	RetentionMs       int64
+}
+
+// TopicInfo summarises a topic in the cluster
+type TopicInfo struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	Internal          bool
+}
+
+// PartitionInfo describes one partition of a topic
+type PartitionInfo struct {
+	ID          int
+	Leader      int
+	Replicas    []int
+	ISR         []int
+	FirstOffset int64
+	LastOffset  int64
+}
+
+// TopicDescription is the detailed state of a topic
+type TopicDescription struct {
+	Name              string
+	ReplicationFactor int
+	Partitions        []PartitionInfo
+	Configs           map[string]string
+}
+
+// PartitionLag is a consumer group's position on one partition
+type PartitionLag struct {
+	Topic           string
+	Partition       int
+	CommittedOffset int64
+	LastOffset      int64
+	Lag             int64
+}
+
+// TopicCreateResult reports what happened to one topic in CreateTopics
+type TopicCreateResult struct {
+	Spec    TopicSpec
+	Created bool
+	Error   error
+}
+
+// adminClient returns a client that sends admin requests over the shared transport
+func (ks *KafkaService) adminClient() (*kafka.Client, error) {
+	if ks.transport == nil {
+		return nil, errors.New("kafka is not configured")
+	}
+	return &kafka.Client{
+		Addr:      kafka.TCP(ks.config.Brokers()...),
+		Transport: ks.transport,
+	}, nil
+}
+
+// ManagedTopics returns the specs of every topic the service produces to or
+// consumes from, with their retry and dead-letter topics
+func (ks *KafkaService) ManagedTopics() []TopicSpec {
+	var specs []TopicSpec
+	for _, topic := range ks.sourceTopics() {
+		specs = append(specs, topicSpec(topic, false))
+		if !ks.retryPolicy.Enabled {
+			continue
+		}
+		for attempt := range ks.retryPolicy.Delays {
+			specs = append(specs, topicSpec(ks.retryPolicy.RetryTopic(topic, attempt+1), false))
+		}
+		specs = append(specs, topicSpec(ks.retryPolicy.DeadLetterTopic(topic), true))
+	}
+	return specs
+}
+
+// TopicSpecFor returns the configured layout for any topic name. Names ending
+// in the dead-letter suffix get the dead-letter retention.
+func TopicSpecFor(name string) TopicSpec {
+	return topicSpec(name, getRetryPolicy().IsDeadLetterTopic(name))
+}
+
+// topicSpec reads a topic's layout from kafka.topics. Dead-letter topics keep
+// messages for dead_letter_retention_ms; an override for the topic name wins.
+func topicSpec(name string, deadLetter bool) TopicSpec {
+	spec := TopicSpec{
+		Name:              name,
+		Partitions:        facades.Config().GetInt("kafka.topics.partitions", 3),
+		ReplicationFactor: facades.Config().GetInt("kafka.topics.replication_factor", 1),
+		RetentionMs:       int64(facades.Config().GetInt("kafka.topics.retention_ms", 604800000)),
+	}
+	if deadLetter {
+		spec.RetentionMs = int64(facades.Config().GetInt("kafka.topics.dead_letter_retention_ms", 2592000000))
+	}
+
+	// Topic names contain dots, so overrides are read as a map rather than by dotted key
+	overrides, _ := facades.Config().Get("kafka.topics.overrides").(map[string]any)
+	if override, ok := overrides[name].(map[string]any); ok {
+		spec = applyTopicOverride(spec, override)
+	}
+	return spec
+}
+
+// applyTopicOverride applies partitions, replication_factor and retention_ms from an override
+func applyTopicOverride(spec TopicSpec, override map[string]any) TopicSpec {
+	value := func(key string) (int64, bool) {
+		raw, ok := override[key]
+		if !ok {
+			return 0, false
+		}
+		parsed, err := strconv.ParseInt(fmt.Sprint(raw), 10, 64)
+		return parsed, err == nil
+	}
+
+	if partitions, ok := value("partitions"); ok {
+		spec.Partitions = int(partitions)
+	}
+	if replicationFactor, ok := value("replication_factor"); ok {
+		spec.ReplicationFactor = int(replicationFactor)
+	}
+	if retention, ok := value("retention_ms"); ok {
+		spec.RetentionMs = retention
+	}
+	return spec
+}
+
+// ListTopics returns every topic in the cluster, sorted by name
+func (ks *KafkaService) ListTopics(ctx context.Context) ([]TopicInfo, error) {
+	client, err := ks.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]TopicInfo, 0, len(metadata.Topics))
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			continue
+		}
+		topics = append(topics, topicInfo(topic))
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+	return topics, nil
+}
+
+// topicInfo summarises a topic from its metadata
+func topicInfo(topic kafka.Topic) TopicInfo {
+	info := TopicInfo{Name: topic.Name, Partitions: len(topic.Partitions), Internal: topic.Internal}
+	if len(topic.Partitions) > 0 {
+		info.ReplicationFactor = len(topic.Partitions[0].Replicas)
+	}
+	return info
+}
+
+// CreateTopics creates the given topics. Topics that already exist are
+// reported as not created without an error; their layout is left unchanged.
+func (ks *KafkaService) CreateTopics(ctx context.Context, specs []TopicSpec, validateOnly bool) ([]TopicCreateResult, error) {
+	client, err := ks.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	request := &kafka.CreateTopicsRequest{ValidateOnly: validateOnly}
+	for _, spec := range specs {
+		request.Topics = append(request.Topics, kafka.TopicConfig{
+			Topic:             spec.Name,
+			NumPartitions:     spec.Partitions,
+			ReplicationFactor: spec.ReplicationFactor,
+			ConfigEntries: []kafka.ConfigEntry{
+				{ConfigName: "retention.ms", ConfigValue: strconv.FormatInt(spec.RetentionMs, 10)},
+			},
+		})
+	}
+
+	response, err := client.CreateTopics(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]TopicCreateResult, 0, len(specs))
+	for _, spec := range specs {
+		result := TopicCreateResult{Spec: spec}
+		switch topicErr := response.Errors[spec.Name]; {
+		case topicErr == nil:
+			result.Created = true
+		case errors.Is(topicErr, kafka.TopicAlreadyExists):
+		default:
+			result.Error = topicErr
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// DescribeTopic returns a topic's partitions with their replicas and offsets, and its configuration
+func (ks *KafkaService) DescribeTopic(ctx context.Context, name string) (*TopicDescription, error) {
+	client, err := ks.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata.Topics) == 0 || errors.Is(metadata.Topics[0].Error, kafka.UnknownTopicOrPartition) {
+		return nil, fmt.Errorf("topic %q does not exist", name)
+	}
+	topic := metadata.Topics[0]
+	if topic.Error != nil {
+		return nil, topic.Error
+	}
+
+	description := &TopicDescription{
+		Name:              topic.Name,
+		ReplicationFactor: topicInfo(topic).ReplicationFactor,
+		Configs:           map[string]string{},
+	}
+	offsets, err := ks.partitionOffsets(ctx, client, map[string][]kafka.Partition{name: topic.Partitions})
+	if err != nil {
+		return nil, err
+	}
+	for _, partition := range topic.Partitions {
+		info := PartitionInfo{ID: partition.ID, Leader: partition.Leader.ID}
+		for _, replica := range partition.Replicas {
+			info.Replicas = append(info.Replicas, replica.ID)
+		}
+		for _, replica := range partition.Isr {
+			info.ISR = append(info.ISR, replica.ID)
+		}
+		if offset, ok := offsets[name][partition.ID]; ok {
+			info.FirstOffset, info.LastOffset = offset.FirstOffset, offset.LastOffset
+		}
+		description.Partitions = append(description.Partitions, info)
+	}
+	sort.Slice(description.Partitions, func(i, j int) bool { return description.Partitions[i].ID < description.Partitions[j].ID })
+
+	configs, err := client.DescribeConfigs(ctx, &kafka.DescribeConfigsRequest{
+		Resources: []kafka.DescribeConfigRequestResource{{ResourceType: kafka.ResourceTypeTopic, ResourceName: name}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range configs.Resources {
+		if resource.Error != nil {
+			return nil, resource.Error
+		}
+		for _, entry := range resource.ConfigEntries {
+			if !entry.IsSensitive {
+				description.Configs[entry.ConfigName] = entry.ConfigValue
+			}
+		}
+	}
+
+	return description, nil
+}
+
+// ConsumerGroupLag returns a group's committed offset and lag on every
+// partition of the given topics. Partitions without a committed offset are
+// lagging by every message they hold.
+func (ks *KafkaService) ConsumerGroupLag(ctx context.Context, groupID string, topics []string) ([]PartitionLag, error) {
+	client, err := ks.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := map[string][]kafka.Partition{}
+	request := &kafka.OffsetFetchRequest{GroupID: groupID, Topics: map[string][]int{}}
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			continue
+		}
+		partitions[topic.Name] = topic.Partitions
+		for _, partition := range topic.Partitions {
+			request.Topics[topic.Name] = append(request.Topics[topic.Name], partition.ID)
+		}
+	}
+	if len(partitions) == 0 {
+		return nil, nil
+	}
+
+	committed, err := client.OffsetFetch(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if committed.Error != nil {
+		return nil, committed.Error
+	}
+
+	offsets, err := ks.partitionOffsets(ctx, client, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	var lags []PartitionLag
+	for topic, topicPartitions := range committed.Topics {
+		for _, partition := range topicPartitions {
+			offset := offsets[topic][partition.Partition]
+			lag := PartitionLag{
+				Topic:           topic,
+				Partition:       partition.Partition,
+				CommittedOffset: partition.CommittedOffset,
+				LastOffset:      offset.LastOffset,
+			}
+			if lag.CommittedOffset < 0 {
+				lag.Lag = offset.LastOffset - offset.FirstOffset
+			} else {
+				lag.Lag = max(offset.LastOffset-lag.CommittedOffset, 0)
+			}
+			lags = append(lags, lag)
+		}
+	}
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+	return lags, nil
+}
+
+// partitionOffsets returns the first and last offsets of the given partitions, by topic and partition
+func (ks *KafkaService) partitionOffsets(ctx context.Context, client *kafka.Client, partitions map[string][]kafka.Partition) (map[string]map[int]kafka.PartitionOffsets, error) {
+	request := &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{}}
+	for topic, topicPartitions := range partitions {
+		for _, partition := range topicPartitions {
+			request.Topics[topic] = append(request.Topics[topic],
+				kafka.FirstOffsetOf(partition.ID),
+				kafka.LastOffsetOf(partition.ID),
+			)
+		}
+	}
+
+	response, err := client.ListOffsets(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := map[string]map[int]kafka.PartitionOffsets{}
+	for topic, topicOffsets := range response.Topics {
+		offsets[topic] = map[int]kafka.PartitionOffsets{}
+		for _, offset := range topicOffsets {
+			if offset.Error != nil {
+				return nil, fmt.Errorf("failed to list offsets for %s/%d: %w", topic, offset.Partition, offset.Error)
+			}
+			offsets[topic][offset.Partition] = offset
+		}
+	}
+	return offsets, nil
+}
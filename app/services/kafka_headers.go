@@ -0,0 +1,90 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers set on every published event so consumers can route, filter and
+// trace messages without decoding the value
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+	HeaderContentType   = "content-type"
+	HeaderCorrelationID = "correlation_id"
+	HeaderTraceParent   = "traceparent"
+	HeaderTraceID       = "trace_id"
+	HeaderProducerHost  = "producer_host"
+	HeaderSubject       = "subject"
+)
+
+// ContentTypeJSON is the content type of envelopes encoded as JSON
+const ContentTypeJSON = "application/json"
+
+// TraceContext ties an event to the request that caused it
+type TraceContext struct {
+	CorrelationID string
+	// TraceParent is the W3C traceparent of the originating request
+	TraceParent string
+}
+
+// TraceID returns the trace ID part of a W3C traceparent
+// ("00-<trace-id>-<parent-id>-<flags>"), or "" if it is malformed
+func (t TraceContext) TraceID() string {
+	parts := strings.Split(t.TraceParent, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
+
+// producerHost is resolved once; the host name does not change while the process runs
+var producerHost = sync.OnceValue(func() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return host
+})
+
+// EventHeaders returns the standard headers for an envelope encoded with the
+// given content type. Optional values are left out when empty.
+func EventHeaders(envelope *EventEnvelope, contentType string) []kafka.Header {
+	headers := []kafka.Header{
+		{Key: HeaderEventID, Value: []byte(envelope.EventID)},
+		{Key: HeaderEventType, Value: []byte(envelope.EventType)},
+		{Key: HeaderSchemaVersion, Value: []byte(strconv.Itoa(envelope.SchemaVersion))},
+		{Key: HeaderContentType, Value: []byte(contentType)},
+	}
+
+	trace := TraceContext{CorrelationID: envelope.CorrelationID, TraceParent: envelope.TraceParent}
+	optional := []kafka.Header{
+		{Key: HeaderCorrelationID, Value: []byte(trace.CorrelationID)},
+		{Key: HeaderTraceParent, Value: []byte(trace.TraceParent)},
+		{Key: HeaderTraceID, Value: []byte(trace.TraceID())},
+		{Key: HeaderProducerHost, Value: []byte(producerHost())},
+		{Key: HeaderSubject, Value: []byte(envelope.Subject)},
+	}
+	for _, header := range optional {
+		if len(header.Value) > 0 {
+			headers = append(headers, header)
+		}
+	}
+
+	return headers
+}
+
+// MessageHeaders returns a message's headers as a map. When a key repeats, the
+// last value wins, matching how retry headers are read.
+func MessageHeaders(msg *kafka.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	return headers
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// BrokerHealth is the result of probing one broker
+type BrokerHealth struct {
+	ID          int    `json:"id"`
+	Address     string `json:"address"`
+	Reachable   bool   `json:"reachable"`
+	APIVersions int    `json:"api_versions,omitempty"`
+	LatencyMs   int64  `json:"latency_ms"`
+	Error       string `json:"error,omitempty"`
+}
+
+// TopicHealth is the state of one topic in the cluster metadata
+type TopicHealth struct {
+	Name                 string `json:"name"`
+	Exists               bool   `json:"exists"`
+	Partitions           int    `json:"partitions"`
+	LeaderlessPartitions []int  `json:"leaderless_partitions,omitempty"`
+	Error                string `json:"error,omitempty"`
+}
+
+// HealthReport describes broker reachability and topic state
+type HealthReport struct {
+	Healthy   bool           `json:"healthy"`
+	ClusterID string         `json:"cluster_id,omitempty"`
+	Brokers   []BrokerHealth `json:"brokers"`
+	Topics    []TopicHealth  `json:"topics"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// kafkaStatus holds the latest health report and consumer group lag. They are
+// refreshed on the metrics interval, so health requests and metric scrapes
+// don't query the brokers themselves.
+type kafkaStatus struct {
+	mu sync.Mutex
+	// refreshed is set once a refresh ran with Kafka enabled
+	refreshed bool
+	health    *HealthReport
+	healthErr error
+	lag       []PartitionLag
+	lagErr    error
+}
+
+// refreshStatus checks the cluster and reads the consumer group's lag. It
+// does nothing while Kafka is disabled.
+func (ks *KafkaService) refreshStatus() {
+	if !ks.IsEnabled() {
+		return
+	}
+
+	healthCtx, cancel := context.WithTimeout(ks.stopped, 5*time.Second)
+	health, healthErr := ks.CheckHealth(healthCtx, ks.HealthTopics())
+	cancel()
+
+	lagCtx, cancel := context.WithTimeout(ks.stopped, 5*time.Second)
+	lag, lagErr := ks.ConsumerGroupLag(lagCtx, ks.config.ConsumerGroupID, ks.ConsumerTopics())
+	cancel()
+
+	ks.status.mu.Lock()
+	defer ks.status.mu.Unlock()
+
+	ks.status.refreshed = true
+	ks.status.health, ks.status.healthErr = health, healthErr
+	ks.status.lag, ks.status.lagErr = lag, lagErr
+}
+
+// resetStatus forgets the cached report and lag, e.g. from before a
+// reconnect, so the next read refreshes them
+func (ks *KafkaService) resetStatus() {
+	ks.status.mu.Lock()
+	defer ks.status.mu.Unlock()
+
+	ks.status.refreshed = false
+	ks.status.health, ks.status.healthErr = nil, nil
+	ks.status.lag, ks.status.lagErr = nil, nil
+}
+
+// refreshStatusOnce refreshes the status if no refresh ran with Kafka enabled
+// yet, for processes that read it before the first sample
+func (ks *KafkaService) refreshStatusOnce() {
+	ks.status.mu.Lock()
+	refreshed := ks.status.refreshed
+	ks.status.mu.Unlock()
+
+	if !refreshed {
+		ks.refreshStatus()
+	}
+}
+
+// Health returns the latest CheckHealth result for HealthTopics, at most one
+// metrics interval old. The report is nil while Kafka is disabled.
+func (ks *KafkaService) Health() (*HealthReport, error) {
+	ks.refreshStatusOnce()
+
+	ks.status.mu.Lock()
+	defer ks.status.mu.Unlock()
+
+	return ks.status.health, ks.status.healthErr
+}
+
+// consumerLag returns the consumer group's latest lag on ConsumerTopics,
+// which is nil while Kafka is disabled
+func (ks *KafkaService) consumerLag() ([]PartitionLag, error) {
+	ks.refreshStatusOnce()
+
+	ks.status.mu.Lock()
+	defer ks.status.mu.Unlock()
+
+	return ks.status.lag, ks.status.lagErr
+}
+
+// HealthTopics returns the topics the service produces to or consumes from,
+// with their retry and dead-letter topics
+func (ks *KafkaService) HealthTopics() []string {
+	return ExpandTopics(ks.sourceTopics(), ks.retryPolicy)
+}
+
+// sourceTopics returns the routed and consumed topics, without retry and
+// dead-letter topics
+func (ks *KafkaService) sourceTopics() []string {
+	return ExpandTopics(slices.Concat(ks.router.Topics(), ks.ConsumerTopics()), nil)
+}
+
+// ExpandTopics adds each topic's retry and dead-letter topics when the policy
+// is enabled, and returns all of them deduplicated and sorted. A nil policy
+// adds nothing.
+func ExpandTopics(topics []string, policy *RetryPolicy) []string {
+	seen := map[string]bool{}
+	var expanded []string
+	add := func(topic string) {
+		if !seen[topic] {
+			seen[topic] = true
+			expanded = append(expanded, topic)
+		}
+	}
+
+	for _, topic := range topics {
+		add(topic)
+		if policy == nil || !policy.Enabled {
+			continue
+		}
+		for attempt := range policy.Delays {
+			add(policy.RetryTopic(topic, attempt+1))
+		}
+		add(policy.DeadLetterTopic(topic))
+	}
+	sort.Strings(expanded)
+	return expanded
+}
+
+// CheckHealth probes the cluster without producing any data. A metadata
+// request lists the brokers and the state of the given topics, then each
+// broker is dialled with the configured TLS and SASL settings and asked for
+// its API versions. An error is returned only when no broker answered the
+// metadata request.
+func (ks *KafkaService) CheckHealth(ctx context.Context, topics []string) (*HealthReport, error) {
+	report := &HealthReport{CheckedAt: time.Now().UTC()}
+	if ks.transport == nil || ks.dialer == nil {
+		return report, errors.New("kafka is not configured")
+	}
+
+	client := &kafka.Client{
+		Addr:      kafka.TCP(ks.config.Brokers()...),
+		Transport: ks.transport,
+	}
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return report, err
+	}
+	report.ClusterID = metadata.ClusterID
+	report.Healthy = len(metadata.Brokers) > 0
+
+	for _, broker := range metadata.Brokers {
+		health := ks.probeBroker(ctx, broker)
+		report.Healthy = report.Healthy && health.Reachable
+		report.Brokers = append(report.Brokers, health)
+	}
+	sort.Slice(report.Brokers, func(i, j int) bool { return report.Brokers[i].ID < report.Brokers[j].ID })
+
+	found := map[string]kafka.Topic{}
+	for _, topic := range metadata.Topics {
+		found[topic.Name] = topic
+	}
+	for _, name := range topics {
+		health := TopicHealth{Name: name}
+		topic, ok := found[name]
+		switch {
+		case !ok || errors.Is(topic.Error, kafka.UnknownTopicOrPartition):
+			health.Error = "topic does not exist"
+		case topic.Error != nil:
+			health.Error = topic.Error.Error()
+		default:
+			health.Exists = true
+			health.Partitions = len(topic.Partitions)
+			for _, partition := range topic.Partitions {
+				if partition.Leader.Host == "" || partition.Error != nil {
+					health.LeaderlessPartitions = append(health.LeaderlessPartitions, partition.ID)
+				}
+			}
+			sort.Ints(health.LeaderlessPartitions)
+		}
+
+		report.Healthy = report.Healthy && health.Exists && len(health.LeaderlessPartitions) == 0
+		report.Topics = append(report.Topics, health)
+	}
+
+	return report, nil
+}
+
+// probeBroker dials a broker and sends an ApiVersions request
+func (ks *KafkaService) probeBroker(ctx context.Context, broker kafka.Broker) BrokerHealth {
+	health := BrokerHealth{
+		ID:      broker.ID,
+		Address: net.JoinHostPort(broker.Host, strconv.Itoa(broker.Port)),
+	}
+
+	start := time.Now()
+	conn, err := ks.dialer.DialContext(ctx, "tcp", health.Address)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	versions, err := conn.ApiVersions()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	health.Reachable = true
+	health.APIVersions = len(versions)
+	health.LatencyMs = time.Since(start).Milliseconds()
+	return health
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/segmentio/kafka-go"
+)
+
+// ClientMetrics summarises a producer's or consumer's traffic. Totals count
+// from process start; the rates cover the last sampling interval.
+type ClientMetrics struct {
+	Messages          int64   `json:"messages"`
+	Bytes             int64   `json:"bytes"`
+	Errors            int64   `json:"errors"`
+	Rebalances        int64   `json:"rebalances,omitempty"`
+	MessagesPerSecond float64 `json:"messages_per_second"`
+	BytesPerSecond    float64 `json:"bytes_per_second"`
+}
+
+// LatencyMetrics summarises publish latency from process start
+type LatencyMetrics struct {
+	Count int64   `json:"count"`
+	AvgMs float64 `json:"avg_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+// MetricsSummary is the metrics overview included in the health response
+type MetricsSummary struct {
+	Producer       ClientMetrics  `json:"producer"`
+	Consumer       ClientMetrics  `json:"consumer"`
+	PublishLatency LatencyMetrics `json:"publish_latency"`
+	// ConsumerLag is the consumer group's total lag per topic
+	ConsumerLag map[string]int64 `json:"consumer_lag,omitempty"`
+}
+
+// kafkaMetrics turns the writers' and readers' Stats() into Prometheus
+// metrics. kafka-go resets its counters on every Stats() call, so the stats
+// are sampled in one place and accumulated here.
+type kafkaMetrics struct {
+	registry *prometheus.Registry
+	interval time.Duration
+
+	producerMessages   prometheus.Counter
+	producerBytes      prometheus.Counter
+	producerErrors     prometheus.Counter
+	producerRetries    prometheus.Counter
+	publishLatency     *prometheus.HistogramVec
+	consumerMessages   prometheus.Counter
+	consumerBytes      prometheus.Counter
+	consumerErrors     prometheus.Counter
+	consumerRebalances prometheus.Counter
+	consumerFetches    prometheus.Counter
+
+	mu       sync.Mutex
+	readers  map[*kafka.Reader]struct{}
+	producer ClientMetrics
+	consumer ClientMetrics
+	latency  LatencyMetrics
+}
+
+// newKafkaMetrics creates the metrics and registers them, with the consumer
+// lag collector for ks, in a registry of their own
+func newKafkaMetrics(ks *KafkaService) *kafkaMetrics {
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Name: name, Help: help})
+	}
+
+	m := &kafkaMetrics{
+		registry:         prometheus.NewRegistry(),
+		interval:         time.Duration(facades.Config().GetInt("kafka.metrics.interval_ms", 10000)) * time.Millisecond,
+		producerMessages: counter("kafka_producer_messages_total", "Messages written to Kafka"),
+		producerBytes:    counter("kafka_producer_bytes_total", "Bytes of message keys and values written to Kafka"),
+		producerErrors:   counter("kafka_producer_errors_total", "Failed Kafka write attempts"),
+		producerRetries:  counter("kafka_producer_retries_total", "Retried Kafka writes"),
+		publishLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kafka_publish_duration_seconds",
+			Help:    "Time from publishing an event until the brokers acknowledged or rejected it",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"topic", "result"}),
+		consumerMessages:   counter("kafka_consumer_messages_total", "Messages fetched from Kafka"),
+		consumerBytes:      counter("kafka_consumer_bytes_total", "Bytes of message keys and values fetched from Kafka"),
+		consumerErrors:     counter("kafka_consumer_errors_total", "Kafka reader errors"),
+		consumerRebalances: counter("kafka_consumer_rebalances_total", "Consumer group rebalances"),
+		consumerFetches:    counter("kafka_consumer_fetches_total", "Fetch requests sent to Kafka"),
+		readers:            map[*kafka.Reader]struct{}{},
+	}
+	if m.interval <= 0 {
+		m.interval = 10 * time.Second
+	}
+
+	m.registry.MustRegister(
+		m.producerMessages, m.producerBytes, m.producerErrors, m.producerRetries, m.publishLatency,
+		m.consumerMessages, m.consumerBytes, m.consumerErrors, m.consumerRebalances, m.consumerFetches,
+		&consumerLagCollector{ks: ks},
+	)
+	return m
+}
+
+// observePublish records how long a publish to topic took
+func (m *kafkaMetrics) observePublish(topic string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.publishLatency.WithLabelValues(topic, result).Observe(duration.Seconds())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ms := float64(duration) / float64(time.Millisecond)
+	m.latency.AvgMs = (m.latency.AvgMs*float64(m.latency.Count) + ms) / float64(m.latency.Count+1)
+	m.latency.Count++
+	m.latency.MaxMs = max(m.latency.MaxMs, ms)
+}
+
+// watchReader includes a reader's stats until the returned function is
+// called, which samples it one last time
+func (m *kafkaMetrics) watchReader(reader *kafka.Reader) func() {
+	m.mu.Lock()
+	m.readers[reader] = struct{}{}
+	m.mu.Unlock()
+
+	return func() {
+		stats := reader.Stats()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.readers, reader)
+		m.addReaderStats(stats)
+	}
+}
+
+// sample adds the stats gathered since the last sample and updates the rates
+func (m *kafkaMetrics) sample(writers []*kafka.Writer, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	producer, consumer := m.producer, m.consumer
+	for _, writer := range writers {
+		if writer != nil {
+			m.addWriterStats(writer.Stats())
+		}
+	}
+	for reader := range m.readers {
+		m.addReaderStats(reader.Stats())
+	}
+
+	seconds := elapsed.Seconds()
+	m.producer.MessagesPerSecond = float64(m.producer.Messages-producer.Messages) / seconds
+	m.producer.BytesPerSecond = float64(m.producer.Bytes-producer.Bytes) / seconds
+	m.consumer.MessagesPerSecond = float64(m.consumer.Messages-consumer.Messages) / seconds
+	m.consumer.BytesPerSecond = float64(m.consumer.Bytes-consumer.Bytes) / seconds
+}
+
+// addWriterStats accumulates a writer's counters; m.mu must be held
+func (m *kafkaMetrics) addWriterStats(stats kafka.WriterStats) {
+	m.producerMessages.Add(float64(stats.Messages))
+	m.producerBytes.Add(float64(stats.Bytes))
+	m.producerErrors.Add(float64(stats.Errors))
+	m.producerRetries.Add(float64(stats.Retries))
+
+	m.producer.Messages += stats.Messages
+	m.producer.Bytes += stats.Bytes
+	m.producer.Errors += stats.Errors
+}
+
+// addReaderStats accumulates a reader's counters; m.mu must be held
+func (m *kafkaMetrics) addReaderStats(stats kafka.ReaderStats) {
+	m.consumerMessages.Add(float64(stats.Messages))
+	m.consumerBytes.Add(float64(stats.Bytes))
+	m.consumerErrors.Add(float64(stats.Errors))
+	m.consumerRebalances.Add(float64(stats.Rebalances))
+	m.consumerFetches.Add(float64(stats.Fetches))
+
+	m.consumer.Messages += stats.Messages
+	m.consumer.Bytes += stats.Bytes
+	m.consumer.Errors += stats.Errors
+	m.consumer.Rebalances += stats.Rebalances
+}
+
+// StartSampling samples the writers and readers, and refreshes the health
+// report and consumer lag, every kafka.metrics.interval_ms until the service
+// is closed. Long-running processes start it; short-lived commands don't.
+func (ks *KafkaService) StartSampling() {
+	ks.sampling.Do(func() {
+		go ks.sampleStats()
+	})
+}
+
+func (ks *KafkaService) sampleStats() {
+	ticker := time.NewTicker(ks.metrics.interval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		var now time.Time
+		select {
+		case <-ks.stopped.Done():
+			return
+		case now = <-ticker.C:
+		}
+
+		ks.mu.RLock()
+		writers := []*kafka.Writer{ks.producer, ks.asyncProducer}
+		ks.mu.RUnlock()
+
+		ks.metrics.sample(writers, now.Sub(last))
+		last = now
+
+		ks.refreshStatus()
+	}
+}
+
+// MetricsHandler serves the Kafka metrics in the Prometheus text format
+func (ks *KafkaService) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(ks.metrics.registry, promhttp.HandlerOpts{})
+}
+
+// ServeMetrics serves MetricsHandler on addr at /metrics until ctx is
+// cancelled. Metrics are kept off the public API, so each process serves them
+// on an address of its own.
+func (ks *KafkaService) ServeMetrics(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", ks.MetricsHandler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// MetricsSummary returns the traffic seen by this process and, when Kafka is
+// enabled, the consumer group's latest lag on the consumed topics
+func (ks *KafkaService) MetricsSummary() (MetricsSummary, error) {
+	ks.metrics.mu.Lock()
+	summary := MetricsSummary{
+		Producer:       ks.metrics.producer,
+		Consumer:       ks.metrics.consumer,
+		PublishLatency: ks.metrics.latency,
+	}
+	ks.metrics.mu.Unlock()
+
+	lags, err := ks.consumerLag()
+	if err != nil {
+		return summary, err
+	}
+	if lags == nil {
+		return summary, nil
+	}
+	summary.ConsumerLag = map[string]int64{}
+	for _, lag := range lags {
+		summary.ConsumerLag[lag.Topic] += lag.Lag
+	}
+	return summary, nil
+}
+
+// consumerLagCollector reports the consumer group's latest lag per partition.
+// Lag is read from the brokers, so any process can report it.
+type consumerLagCollector struct {
+	ks *KafkaService
+}
+
+var consumerLagDesc = prometheus.NewDesc(
+	"kafka_consumer_lag",
+	"Messages on a partition not yet committed by the consumer group",
+	[]string{"group", "topic", "partition"}, nil,
+)
+
+func (c *consumerLagCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- consumerLagDesc
+}
+
+func (c *consumerLagCollector) Collect(metrics chan<- prometheus.Metric) {
+	lags, err := c.ks.consumerLag()
+	if err != nil {
+		facades.Log().Warning("Failed to collect Kafka consumer lag: " + err.Error())
+		return
+	}
+
+	group := c.ks.config.ConsumerGroupID
+	for _, lag := range lags {
+		metrics <- prometheus.MustNewConstMetric(consumerLagDesc, prometheus.GaugeValue, float64(lag.Lag),
+			group, lag.Topic, strconv.Itoa(lag.Partition))
+	}
+}
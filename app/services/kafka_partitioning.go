@@ -0,0 +1,129 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// Partition key strategies. Messages with the same key land on the same
+// partition, so events sharing a key are consumed in the order they were published.
+const (
+	// PartitionKeySubject keys by the aggregate the event describes, e.g. "activities/42"
+	PartitionKeySubject = "subject"
+	// PartitionKeyEventType keys by event type, sending every event of a type to one partition
+	PartitionKeyEventType = "event_type"
+	// PartitionKeyEventID keys by event ID, spreading events without any ordering
+	PartitionKeyEventID = "event_id"
+	// partitionKeyDataPrefix keys by a top-level field of the event data, e.g. "data.bay_session_id"
+	partitionKeyDataPrefix = "data."
+)
+
+// balancers maps kafka.partitioner values to balancers. Murmur2 matches the
+// Java client and librdkafka, so other producers place the same keys on the
+// same partitions.
+var balancers = map[string]func() kafka.Balancer{
+	"murmur2":     func() kafka.Balancer { return kafka.Murmur2Balancer{} },
+	"fnv1a":       func() kafka.Balancer { return &kafka.Hash{} },
+	"crc32":       func() kafka.Balancer { return kafka.CRC32Balancer{} },
+	"round_robin": func() kafka.Balancer { return &kafka.RoundRobin{} },
+}
+
+// newBalancer returns the balancer for a kafka.partitioner value
+func newBalancer(name string) (kafka.Balancer, error) {
+	if name == "" {
+		name = "murmur2"
+	}
+	factory, ok := balancers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported kafka.partitioner %q (expected murmur2, fnv1a, crc32 or round_robin)", name)
+	}
+	return factory(), nil
+}
+
+// PartitionKeyResolver chooses the message key for each event type. Patterns
+// use the same syntax as topic routes; unmatched event types are keyed by subject.
+type PartitionKeyResolver struct {
+	strategies map[string]string
+}
+
+// NewPartitionKeyResolver creates a resolver from a map of event type patterns to strategies
+func NewPartitionKeyResolver(strategies map[string]string) (*PartitionKeyResolver, error) {
+	resolver := &PartitionKeyResolver{strategies: map[string]string{}}
+	for pattern, strategy := range strategies {
+		pattern, strategy = strings.TrimSpace(pattern), strings.TrimSpace(strategy)
+		if pattern == "" || strategy == "" {
+			continue
+		}
+
+		switch {
+		case strategy == PartitionKeySubject, strategy == PartitionKeyEventType, strategy == PartitionKeyEventID:
+		case strings.HasPrefix(strategy, partitionKeyDataPrefix) && len(strategy) > len(partitionKeyDataPrefix):
+		default:
+			return nil, fmt.Errorf("unsupported kafka.partition_keys strategy %q for %q (expected subject, event_type, event_id or data.<field>)", strategy, pattern)
+		}
+		resolver.strategies[pattern] = strategy
+	}
+	return resolver, nil
+}
+
+// getPartitionKeyResolver reads the key strategies from kafka.partition_keys
+func getPartitionKeyResolver() (*PartitionKeyResolver, error) {
+	strategies := map[string]string{}
+	if configured, ok := facades.Config().Get("kafka.partition_keys").(map[string]any); ok {
+		for pattern, strategy := range configured {
+			strategies[pattern] = fmt.Sprint(strategy)
+		}
+	}
+	return NewPartitionKeyResolver(strategies)
+}
+
+// Key returns the message key for an envelope. It returns nil when the chosen
+// value is empty, e.g. an event without a subject, and the partitioner then
+// spreads such events across partitions.
+func (r *PartitionKeyResolver) Key(envelope *EventEnvelope) []byte {
+	strategy, ok := lookupEventType(r.strategies, envelope.EventType)
+	if !ok {
+		strategy = PartitionKeySubject
+	}
+
+	var key string
+	switch strategy {
+	case PartitionKeySubject:
+		key = envelope.Subject
+	case PartitionKeyEventType:
+		key = envelope.EventType
+	case PartitionKeyEventID:
+		key = envelope.EventID
+	default:
+		key = dataField(envelope.Data, strings.TrimPrefix(strategy, partitionKeyDataPrefix))
+	}
+
+	if key == "" {
+		return nil
+	}
+	return []byte(key)
+}
+
+// dataField returns a top-level field of the event data as a string, or "" if
+// the data is not an object or the field is missing or null
+func dataField(data json.RawMessage, field string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+
+	raw, ok := fields[field]
+	if !ok || string(raw) == "null" {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// PublishCallback is called once an asynchronously published event has been
+// acknowledged by the brokers, or has failed. A failed event has already been
+// buffered in the outbox when the callback runs, unless buffering failed too.
+// It runs on the writer's goroutine and should return quickly.
+type PublishCallback func(envelope *EventEnvelope, err error)
+
+// asyncPublish travels with an async message so its callback can be found on completion
+type asyncPublish struct {
+	envelope *EventEnvelope
+	callback PublishCallback
+	queuedAt time.Time
+}
+
+// compressionCodecs maps kafka.compression values to codecs
+var compressionCodecs = map[string]kafka.Compression{
+	"gzip":   kafka.Gzip,
+	"snappy": kafka.Snappy,
+	"lz4":    kafka.Lz4,
+	"zstd":   kafka.Zstd,
+}
+
+// newTransport builds the transport shared by the writers from the dialer settings
+func newTransport(dialer *kafka.Dialer) *kafka.Transport {
+	return &kafka.Transport{
+		ClientID:    dialer.ClientID,
+		DialTimeout: dialer.Timeout,
+		TLS:         dialer.TLS,
+		SASL:        dialer.SASLMechanism,
+	}
+}
+
+// newWriter builds a writer from the producer settings that publishes over
+// the shared transport
+func (ks *KafkaService) newWriter(async bool) (*kafka.Writer, error) {
+	writer, err := NewWriter(ks.config)
+	if err != nil {
+		return nil, err
+	}
+
+	writer.Transport = ks.transport
+	writer.Async = async
+	if async {
+		writer.Completion = ks.completeAsync
+	}
+	return writer, nil
+}
+
+// NewWriter maps the producer settings onto a writer: acks, retries and
+// backoff, batching, timeouts, compression and the partitioner. The topic is
+// set per message so the same writer can forward to retry and dead-letter topics.
+func NewWriter(cfg *KafkaConfig) (*kafka.Writer, error) {
+	var acks kafka.RequiredAcks
+	switch cfg.Acks {
+	case -1, 0, 1:
+		acks = kafka.RequiredAcks(cfg.Acks)
+	default:
+		return nil, fmt.Errorf("unsupported kafka.acks %d (expected -1, 0 or 1)", cfg.Acks)
+	}
+
+	var compression kafka.Compression
+	if codec := strings.ToLower(cfg.Compression); codec != "" && codec != "none" {
+		var ok bool
+		if compression, ok = compressionCodecs[codec]; !ok {
+			return nil, fmt.Errorf("unsupported kafka.compression %q (expected none, gzip, snappy, lz4 or zstd)", cfg.Compression)
+		}
+	}
+
+	balancer, err := newBalancer(cfg.Partitioner)
+	if err != nil {
+		return nil, err
+	}
+
+	requestTimeout := time.Duration(cfg.RequestTimeoutMs) * time.Millisecond
+	return &kafka.Writer{
+		Addr:            kafka.TCP(cfg.Brokers()...),
+		Balancer:        balancer,
+		RequiredAcks:    acks,
+		MaxAttempts:     cfg.Retries + 1,
+		WriteBackoffMin: time.Duration(cfg.RetryBackoffMs) * time.Millisecond,
+		WriteBackoffMax: time.Duration(cfg.RetryBackoffMaxMs) * time.Millisecond,
+		BatchSize:       cfg.BatchSize,
+		BatchBytes:      int64(cfg.BatchBytes),
+		BatchTimeout:    time.Duration(cfg.BatchTimeoutMs) * time.Millisecond,
+		ReadTimeout:     requestTimeout,
+		WriteTimeout:    requestTimeout,
+		Compression:     compression,
+	}, nil
+}
+
+// PublishEventAsync queues an event and returns without waiting for the
+// brokers. The callback, which may be nil, receives the delivery result. While
+// Kafka is down, or when the delivery fails, the event is buffered in the
+// outbox and relayed later; the callback then receives the error. Events that
+// must survive a crash before delivery should be recorded in the outbox instead.
+func (ks *KafkaService) PublishEventAsync(eventType string, data interface{}, callback PublishCallback) error {
+	envelope, err := NewEventEnvelope(eventType, data, "")
+	if err != nil {
+		facades.Log().Error("Failed to marshal event: " + err.Error())
+		return err
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if !ks.enabled {
+		if err := ks.bufferEvent(envelope, ErrKafkaDisabled); err != nil {
+			return err
+		}
+		if callback != nil {
+			callback(envelope, ErrKafkaDisabled)
+		}
+		return nil
+	}
+
+	msg, err := ks.message(context.Background(), envelope)
+	if err != nil {
+		return err
+	}
+	msg.WriterData = &asyncPublish{envelope: envelope, callback: callback, queuedAt: time.Now()}
+
+	// An async writer only returns errors for invalid messages; delivery
+	// results are reported to completeAsync
+	return ks.asyncProducer.WriteMessages(context.Background(), msg)
+}
+
+// completeAsync reports the result of an async batch to each message's callback
+func (ks *KafkaService) completeAsync(messages []kafka.Message, err error) {
+	for _, msg := range messages {
+		pending, ok := msg.WriterData.(*asyncPublish)
+		if !ok {
+			continue
+		}
+		ks.metrics.observePublish(msg.Topic, time.Since(pending.queuedAt), err)
+
+		if err != nil {
+			facades.Log().Error("Failed to publish event to Kafka: "+err.Error(), map[string]interface{}{
+				"event_type": pending.envelope.EventType,
+				"event_id":   pending.envelope.EventID,
+				"topic":      msg.Topic,
+			})
+			// Keep the event for the relay; a copy the brokers did store is
+			// dropped by consumers by its event ID
+			if bufferErr := ks.bufferEvent(pending.envelope, err); bufferErr != nil {
+				err = errors.Join(err, bufferErr)
+			}
+		} else {
+			facades.Log().Debug("Event published to Kafka: type=" + pending.envelope.EventType + ", id=" + pending.envelope.EventID + ", topic=" + msg.Topic)
+		}
+
+		if pending.callback != nil {
+			pending.callback(pending.envelope, err)
+		}
+	}
+}
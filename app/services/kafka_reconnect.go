@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// ReconnectPolicy controls how KafkaService reconnects after a failed startup check
+type ReconnectPolicy struct {
+	Enabled          bool
+	InitialBackoff   time.Duration
+	MaxBackoff       time.Duration
+	FlushOnReconnect bool
+}
+
+// getReconnectPolicy reads the reconnect policy from kafka.reconnect
+func getReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		Enabled:          facades.Config().GetBool("kafka.reconnect.enabled", true),
+		InitialBackoff:   time.Duration(facades.Config().GetInt("kafka.reconnect.initial_backoff_ms", 1000)) * time.Millisecond,
+		MaxBackoff:       time.Duration(facades.Config().GetInt("kafka.reconnect.max_backoff_ms", 60000)) * time.Millisecond,
+		FlushOnReconnect: facades.Config().GetBool("kafka.reconnect.flush_on_reconnect", true),
+	}
+}
+
+// Retry calls probe with exponential backoff, starting after InitialBackoff,
+// until it succeeds or ctx is cancelled, in which case it returns ctx's error
+func (p *ReconnectPolicy) Retry(ctx context.Context, probe func(ctx context.Context) error) error {
+	backoff := p.InitialBackoff
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		err := probe(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		backoff = min(backoff*2, p.MaxBackoff)
+		facades.Log().Warning("Kafka reconnect failed: "+err.Error(), map[string]interface{}{
+			"retry_in": backoff.String(),
+		})
+		timer.Reset(backoff)
+	}
+}
+
+// reconnect repeats the connectivity check until the brokers answer, then
+// re-enables publishing and flushes the events that were buffered in the
+// outbox while Kafka was unavailable. It gives up when the service is closed.
+func (ks *KafkaService) reconnect() {
+	err := ks.reconnectPolicy.Retry(ks.stopped, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_, err := ks.CheckHealth(ctx, ks.HealthTopics())
+		return err
+	})
+	if err != nil {
+		ks.mu.Lock()
+		ks.reconnecting = false
+		ks.mu.Unlock()
+		return
+	}
+
+	ks.mu.Lock()
+	ks.enabled = true
+	ks.reconnecting = false
+	close(ks.ready)
+	ks.mu.Unlock()
+	ks.resetStatus()
+	facades.Log().Info("Kafka connection restored with profile: " + ks.config.Profile)
+
+	if ks.reconnectPolicy.FlushOnReconnect {
+		ks.flushBuffer()
+	}
+}
+
+// flushBuffer relays the events buffered in the outbox. Other processes may
+// be relaying at the same time; the relay claims the events it sends.
+func (ks *KafkaService) flushBuffer() {
+	sent, err := NewOutboxRelay(ks).Drain(ks.stopped)
+	if err != nil {
+		facades.Log().Error("Failed to flush buffered Kafka events: " + err.Error())
+	}
+	if sent > 0 {
+		facades.Log().Info("Flushed buffered Kafka events", map[string]interface{}{
+			"count": sent,
+		})
+	}
+}
+
+// bufferEvent stores an event that could not be published in the outbox, from
+// where it is relayed once Kafka is reachable again
+func (ks *KafkaService) bufferEvent(envelope *EventEnvelope, reason error) error {
+	if err := recordEnvelope(facades.Orm().Query(), envelope); err != nil {
+		facades.Log().Error("Failed to buffer Kafka event: "+err.Error(), map[string]interface{}{
+			"event_type": envelope.EventType,
+			"event_id":   envelope.EventID,
+		})
+		return err
+	}
+
+	facades.Log().Warning("Event buffered in outbox: "+reason.Error(), map[string]interface{}{
+		"event_type": envelope.EventType,
+		"event_id":   envelope.EventID,
+	})
+	return nil
+}
+
+// WaitForConnection blocks until Kafka is enabled or ctx is cancelled. It
+// returns ErrKafkaDisabled straight away when no reconnect is in progress.
+func (ks *KafkaService) WaitForConnection(ctx context.Context) error {
+	ks.mu.RLock()
+	ready := ks.ready
+	unavailable := !ks.enabled && !ks.reconnecting
+	ks.mu.RUnlock()
+
+	if unavailable {
+		return ErrKafkaDisabled
+	}
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
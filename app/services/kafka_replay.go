@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// ReplayOptions selects what a replay reads and how it is handled. Exactly one
+// of FromBeginning, FromOffset or FromTime must be set.
+type ReplayOptions struct {
+	Topics        []string
+	FromBeginning bool
+	// FromOffset starts every partition at this offset, clamped to the offsets it still holds
+	FromOffset *int64
+	// FromTime starts every partition at its first message at or after this time
+	FromTime *time.Time
+	// GroupID names the consumer group used for the replay. A new throwaway
+	// group is created when empty; passing an earlier group resumes it.
+	GroupID string
+	// DryRun decodes the events and counts them without running handlers
+	DryRun bool
+	Limit  int
+	// IdleTimeout stops the replay when no message arrives within it; it must be positive
+	IdleTimeout time.Duration
+}
+
+// ReplayResult summarises a replay
+type ReplayResult struct {
+	GroupID    string
+	Read       int
+	Handled    int
+	Failed     int
+	EventTypes map[string]int
+}
+
+// Replay re-reads topics from a chosen position into a separate consumer group
+// and runs the registered handlers again, bypassing deduplication, so derived
+// data can be rebuilt after a handler fix. The live consumer group's offsets
+// are not touched and failed events are not forwarded to the retry topics.
+// Replay stops once it reaches the end offsets seen at start, after limit
+// events (0 for no limit), or when no message arrives within IdleTimeout.
+func (ks *KafkaService) Replay(ctx context.Context, options ReplayOptions) (*ReplayResult, error) {
+	if len(options.Topics) == 0 {
+		return nil, errors.New("no topics to replay")
+	}
+	if options.IdleTimeout <= 0 {
+		return nil, errors.New("replay idle timeout must be positive")
+	}
+	if !ks.IsEnabled() {
+		return nil, ErrKafkaDisabled
+	}
+
+	client, err := ks.adminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: options.Topics})
+	if err != nil {
+		return nil, err
+	}
+	partitions := map[string][]kafka.Partition{}
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			return nil, fmt.Errorf("topic %q: %w", topic.Name, topic.Error)
+		}
+		partitions[topic.Name] = topic.Partitions
+	}
+
+	offsets, err := ks.partitionOffsets(ctx, client, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{GroupID: options.GroupID, EventTypes: map[string]int{}}
+	starts := map[string]map[int]int64{}
+	if result.GroupID == "" {
+		result.GroupID = ks.config.ConsumerGroupID + ".replay." + strconv.FormatInt(time.Now().Unix(), 10)
+		if starts, err = ks.seedReplayOffsets(ctx, client, result.GroupID, options, partitions, offsets); err != nil {
+			return nil, err
+		}
+	}
+
+	// Partitions are done once they reach the end offset seen now; those with nothing to read already are
+	remaining := map[string]int64{}
+	for topic, topicOffsets := range offsets {
+		for partition, offset := range topicOffsets {
+			start, ok := starts[topic][partition]
+			if !ok {
+				start = offset.FirstOffset
+			}
+			if offset.LastOffset > start {
+				remaining[topic+"/"+strconv.Itoa(partition)] = offset.LastOffset
+			}
+		}
+	}
+
+	readerConfig := ks.readerConfig
+	readerConfig.GroupID = result.GroupID
+	readerConfig.GroupTopics = options.Topics
+	readerConfig.CommitInterval = 0
+	reader := kafka.NewReader(readerConfig)
+	defer reader.Close()
+
+	facades.Log().Info("Starting Kafka replay", map[string]interface{}{
+		"topics":   strings.Join(options.Topics, ","),
+		"group_id": result.GroupID,
+		"dry_run":  options.DryRun,
+	})
+
+	for len(remaining) > 0 && (options.Limit == 0 || result.Read < options.Limit) {
+		fetchCtx, cancel := context.WithTimeout(ctx, options.IdleTimeout)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return result, nil
+			}
+			return result, err
+		}
+
+		key := msg.Topic + "/" + strconv.Itoa(msg.Partition)
+		if end, ok := remaining[key]; !ok || msg.Offset >= end {
+			// Published after the replay started; the live consumer handles it
+			delete(remaining, key)
+			continue
+		}
+		if msg.Offset+1 >= remaining[key] {
+			delete(remaining, key)
+		}
+
+		result.Read++
+		if err := ks.replayMessage(ctx, &msg, options.DryRun, result); err != nil {
+			result.Failed++
+			facades.Log().Error("Replayed event failed: "+err.Error(), map[string]interface{}{
+				"topic":     msg.Topic,
+				"partition": msg.Partition,
+				"offset":    msg.Offset,
+			})
+		}
+
+		// Commit as we go so an interrupted replay can be resumed with the same group
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// replayMessage decodes a message and, unless dryRun is set, dispatches it to the registered handlers
+func (ks *KafkaService) replayMessage(ctx context.Context, msg *kafka.Message, dryRun bool, result *ReplayResult) error {
+	envelope, err := ks.decode(ctx, msg)
+	if err != nil {
+		return err
+	}
+	result.EventTypes[envelope.EventType]++
+
+	if dryRun {
+		return nil
+	}
+	if err := EventHandlers().Dispatch(ctx, &Event{EventEnvelope: envelope, Message: msg}); err != nil {
+		return err
+	}
+	result.Handled++
+	return nil
+}
+
+// seedReplayOffsets commits the starting offset of every partition for a new
+// replay group, so its reader starts there instead of at auto_offset_reset.
+// It returns the committed offsets by topic and partition.
+func (ks *KafkaService) seedReplayOffsets(ctx context.Context, client *kafka.Client, groupID string, options ReplayOptions, partitions map[string][]kafka.Partition, offsets map[string]map[int]kafka.PartitionOffsets) (map[string]map[int]int64, error) {
+	var timeOffsets map[string]map[int]int64
+	if options.FromTime != nil {
+		var err error
+		if timeOffsets, err = offsetsForTime(ctx, client, partitions, *options.FromTime); err != nil {
+			return nil, err
+		}
+	}
+
+	starts := map[string]map[int]int64{}
+	request := &kafka.OffsetCommitRequest{GroupID: groupID, GenerationID: -1, Topics: map[string][]kafka.OffsetCommit{}}
+	for topic, topicPartitions := range partitions {
+		starts[topic] = map[int]int64{}
+		for _, partition := range topicPartitions {
+			bounds := offsets[topic][partition.ID]
+			start := bounds.FirstOffset
+			switch {
+			case options.FromOffset != nil:
+				start = min(max(*options.FromOffset, bounds.FirstOffset), bounds.LastOffset)
+			case options.FromTime != nil:
+				start = bounds.LastOffset
+				if offset, ok := timeOffsets[topic][partition.ID]; ok {
+					start = offset
+				}
+			}
+			starts[topic][partition.ID] = start
+			request.Topics[topic] = append(request.Topics[topic], kafka.OffsetCommit{Partition: partition.ID, Offset: start})
+		}
+	}
+
+	response, err := client.OffsetCommit(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for topic, topicPartitions := range response.Topics {
+		for _, partition := range topicPartitions {
+			if partition.Error != nil {
+				return nil, fmt.Errorf("failed to seed replay offset for %s/%d: %w", topic, partition.Partition, partition.Error)
+			}
+		}
+	}
+	return starts, nil
+}
+
+// offsetsForTime returns the first offset at or after t on each partition.
+// Partitions with no such message are left out.
+func offsetsForTime(ctx context.Context, client *kafka.Client, partitions map[string][]kafka.Partition, t time.Time) (map[string]map[int]int64, error) {
+	request := &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{}}
+	for topic, topicPartitions := range partitions {
+		for _, partition := range topicPartitions {
+			request.Topics[topic] = append(request.Topics[topic], kafka.TimeOffsetOf(partition.ID, t))
+		}
+	}
+
+	response, err := client.ListOffsets(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]map[int]int64{}
+	for topic, topicOffsets := range response.Topics {
+		result[topic] = map[int]int64{}
+		for _, offset := range topicOffsets {
+			if offset.Error != nil {
+				return nil, fmt.Errorf("failed to find offsets for %s/%d: %w", topic, offset.Partition, offset.Error)
+			}
+			found := make([]int64, 0, len(offset.Offsets))
+			for position := range offset.Offsets {
+				if position >= 0 {
+					found = append(found, position)
+				}
+			}
+			if len(found) > 0 {
+				sort.Slice(found, func(i, j int) bool { return found[i] < found[j] })
+				result[topic][offset.Partition] = found[0]
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers added to messages forwarded to retry and dead-letter topics
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderAttempt           = "x-attempt"
+	HeaderErrorMessage      = "x-error-message"
+	HeaderFailedAt          = "x-failed-at"
+	HeaderRetryAt           = "x-retry-at"
+)
+
+// failureHeaders are replaced each time a message fails and dropped when it is replayed
+var failureHeaders = []string{HeaderAttempt, HeaderErrorMessage, HeaderFailedAt, HeaderRetryAt}
+
+// RetryPolicy describes how failed messages move through retry topics to the dead-letter topic
+type RetryPolicy struct {
+	Enabled               bool
+	Delays                []time.Duration
+	RetryTopicSuffix      string
+	DeadLetterTopicSuffix string
+}
+
+// getRetryPolicy reads the retry policy from kafka.retry
+func getRetryPolicy() *RetryPolicy {
+	policy := &RetryPolicy{
+		Enabled:               facades.Config().GetBool("kafka.retry.enabled", true),
+		RetryTopicSuffix:      facades.Config().GetString("kafka.retry.retry_topic_suffix", ".retry"),
+		DeadLetterTopicSuffix: facades.Config().GetString("kafka.retry.dead_letter_topic_suffix", ".dlq"),
+	}
+
+	for _, delay := range strings.Split(facades.Config().GetString("kafka.retry.delays_ms", "5000,30000,300000"), ",") {
+		ms, err := strconv.Atoi(strings.TrimSpace(delay))
+		if err != nil || ms < 0 {
+			continue
+		}
+		policy.Delays = append(policy.Delays, time.Duration(ms)*time.Millisecond)
+	}
+
+	return policy
+}
+
+// RetryTopic returns the retry topic for the given attempt (starting at 1)
+func (p *RetryPolicy) RetryTopic(topic string, attempt int) string {
+	return topic + p.RetryTopicSuffix + "." + strconv.Itoa(attempt)
+}
+
+// DeadLetterTopic returns the dead-letter topic for a source topic
+func (p *RetryPolicy) DeadLetterTopic(topic string) string {
+	return topic + p.DeadLetterTopicSuffix
+}
+
+// IsDeadLetterTopic reports whether a topic name carries the dead-letter suffix
+func (p *RetryPolicy) IsDeadLetterTopic(topic string) bool {
+	return p.DeadLetterTopicSuffix != "" && strings.HasSuffix(topic, p.DeadLetterTopicSuffix)
+}
+
+// Forward builds the message for a message whose handler failed: the next
+// retry topic, or the dead-letter topic once all retry delays are used up.
+// The message keeps its key, value and headers, and gains error metadata
+// headers; the attempt it records is returned alongside.
+func (p *RetryPolicy) Forward(msg *kafka.Message, handlerErr error, now time.Time) (kafka.Message, int) {
+	originalTopic := headerValue(msg.Headers, HeaderOriginalTopic)
+	if originalTopic == "" {
+		originalTopic = msg.Topic
+	}
+	attempt, _ := strconv.Atoi(headerValue(msg.Headers, HeaderAttempt))
+	attempt++
+
+	now = now.UTC()
+	forward := kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: withoutHeaders(msg.Headers, failureHeaders...),
+	}
+	if headerValue(msg.Headers, HeaderOriginalTopic) == "" {
+		forward.Headers = append(forward.Headers,
+			kafka.Header{Key: HeaderOriginalTopic, Value: []byte(msg.Topic)},
+			kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+			kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		)
+	}
+	forward.Headers = append(forward.Headers,
+		kafka.Header{Key: HeaderAttempt, Value: []byte(strconv.Itoa(attempt))},
+		kafka.Header{Key: HeaderErrorMessage, Value: []byte(handlerErr.Error())},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(now.Format(time.RFC3339Nano))},
+	)
+
+	if attempt <= len(p.Delays) {
+		forward.Topic = p.RetryTopic(originalTopic, attempt)
+		forward.Headers = append(forward.Headers, kafka.Header{
+			Key:   HeaderRetryAt,
+			Value: []byte(now.Add(p.Delays[attempt-1]).Format(time.RFC3339Nano)),
+		})
+	} else {
+		forward.Topic = p.DeadLetterTopic(originalTopic)
+	}
+
+	return forward, attempt
+}
+
+// Replay builds the message that re-publishes a dead letter to its original
+// topic, or to topic if it has none, with the failure headers removed
+func (p *RetryPolicy) Replay(msg *kafka.Message, topic string) kafka.Message {
+	destination := headerValue(msg.Headers, HeaderOriginalTopic)
+	if destination == "" {
+		destination = topic
+	}
+
+	return kafka.Message{
+		Topic:   destination,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: withoutHeaders(msg.Headers, append(failureHeaders, HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset)...),
+	}
+}
+
+// RetryWait returns how long a message read from a retry topic must wait
+// before its handler runs again; zero when it is due or has no retry time
+func RetryWait(msg *kafka.Message, now time.Time) time.Duration {
+	retryAt, err := time.Parse(time.RFC3339Nano, headerValue(msg.Headers, HeaderRetryAt))
+	if err != nil || !retryAt.After(now) {
+		return 0
+	}
+	return retryAt.Sub(now)
+}
+
+// handleFailure forwards a message whose handler failed along the retry policy
+func (ks *KafkaService) handleFailure(ctx context.Context, msg *kafka.Message, handlerErr error) error {
+	policy := ks.retryPolicy
+	if !policy.Enabled {
+		return handlerErr
+	}
+
+	forward, attempt := policy.Forward(msg, handlerErr, time.Now())
+	if err := ks.producer.WriteMessages(ctx, forward); err != nil {
+		return errors.Join(handlerErr, err)
+	}
+
+	facades.Log().Warning("Kafka message forwarded after handler failure", map[string]interface{}{
+		"topic":       msg.Topic,
+		"offset":      msg.Offset,
+		"key":         string(msg.Key),
+		"attempt":     attempt,
+		"destination": forward.Topic,
+		"error":       handlerErr.Error(),
+	})
+	return nil
+}
+
+// consumeRetryTopic handles messages from one retry topic, waiting for each
+// message's retry time before running the handler again. Fetching and waiting
+// stop with ctx; a message already being handled finishes on workCtx.
+func (ks *KafkaService) consumeRetryTopic(ctx context.Context, workCtx context.Context, reader *kafka.Reader, handler MessageHandler) {
+	for {
+		msg, err := ks.fetchMessage(ctx, reader)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			facades.Log().Error("Error reading Kafka retry message: " + err.Error())
+			continue
+		}
+
+		if wait := RetryWait(&msg, time.Now()); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		if err := ks.processMessage(workCtx, &msg, handler); err != nil {
+			if workCtx.Err() != nil {
+				return
+			}
+			facades.Log().Error("Failed to forward Kafka retry message: "+err.Error(), map[string]interface{}{
+				"topic":  msg.Topic,
+				"offset": msg.Offset,
+				"key":    string(msg.Key),
+			})
+			continue
+		}
+
+		ks.commitMessage(reader, &msg)
+	}
+}
+
+// ReplayDeadLetters re-publishes messages from the dead-letter topic of the
+// given source topic back to their original topic, with failure headers
+// removed. It stops after limit messages (0 for no limit) or once no message
+// arrives within idleTimeout, which must be positive, and returns the number
+// of messages replayed.
+func (ks *KafkaService) ReplayDeadLetters(ctx context.Context, topic string, limit int, idleTimeout time.Duration) (int, error) {
+	if idleTimeout <= 0 {
+		return 0, errors.New("dead letter replay idle timeout must be positive")
+	}
+	if !ks.IsEnabled() {
+		return 0, ErrKafkaDisabled
+	}
+
+	readerConfig := ks.readerConfig
+	readerConfig.Topic = ks.retryPolicy.DeadLetterTopic(topic)
+	readerConfig.GroupID = ks.config.ConsumerGroupID + ".dlq-replay"
+	readerConfig.StartOffset = kafka.FirstOffset
+	reader := kafka.NewReader(readerConfig)
+	defer reader.Close()
+
+	replayed := 0
+	for limit == 0 || replayed < limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return replayed, nil
+			}
+			return replayed, err
+		}
+
+		if err := ks.producer.WriteMessages(ctx, ks.retryPolicy.Replay(&msg, topic)); err != nil {
+			return replayed, err
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// headerValue returns the value of the last header with the given key
+func headerValue(headers []kafka.Header, key string) string {
+	for i := len(headers) - 1; i >= 0; i-- {
+		if headers[i].Key == key {
+			return string(headers[i].Value)
+		}
+	}
+	return ""
+}
+
+// withoutHeaders returns a copy of headers with the given keys removed
+func withoutHeaders(headers []kafka.Header, keys ...string) []kafka.Header {
+	result := make([]kafka.Header, 0, len(headers))
+	for _, header := range headers {
+		drop := false
+		for _, key := range keys {
+			if header.Key == key {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			result = append(result, header)
+		}
+	}
+	return result
+}
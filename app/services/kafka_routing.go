@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goravel/framework/facades"
+)
+
+// TopicRouter maps event types to the topics they are published to. Patterns
+// use the same syntax as handler registration: an exact event type, a prefix
+// wildcard such as "bay_session.*", or "*". The most specific pattern wins.
+type TopicRouter struct {
+	routes       map[string]string
+	defaultTopic string
+}
+
+// NewTopicRouter creates a router; event types matching no route go to defaultTopic
+func NewTopicRouter(routes map[string]string, defaultTopic string) *TopicRouter {
+	router := &TopicRouter{
+		routes:       map[string]string{},
+		defaultTopic: defaultTopic,
+	}
+	for pattern, topic := range routes {
+		if pattern = strings.TrimSpace(pattern); pattern != "" && topic != "" {
+			router.routes[pattern] = topic
+		}
+	}
+	return router
+}
+
+// getTopicRouter reads the routing table from kafka.routes, falling back to
+// kafka.activity_events_topic for unrouted event types
+func getTopicRouter(defaultTopic string) *TopicRouter {
+	routes := map[string]string{}
+	if configured, ok := facades.Config().Get("kafka.routes").(map[string]any); ok {
+		for pattern, topic := range configured {
+			routes[pattern] = fmt.Sprint(topic)
+		}
+	}
+	return NewTopicRouter(routes, defaultTopic)
+}
+
+// Route returns the topic for an event type. An exact match beats the longest
+// matching prefix wildcard, which beats "*".
+func (r *TopicRouter) Route(eventType string) string {
+	if topic, ok := lookupEventType(r.routes, eventType); ok {
+		return topic
+	}
+	return r.defaultTopic
+}
+
+// lookupEventType returns the value of the most specific pattern in table that
+// matches an event type: an exact match, then the longest prefix wildcard, then "*"
+func lookupEventType(table map[string]string, eventType string) (string, bool) {
+	if value, ok := table[eventType]; ok {
+		return value, true
+	}
+
+	best := ""
+	for pattern := range table {
+		if pattern == "*" || !strings.HasSuffix(pattern, "*") || !matchesEventType(pattern, eventType) {
+			continue
+		}
+		if len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best = pattern
+		}
+	}
+	if best != "" {
+		return table[best], true
+	}
+
+	value, ok := table["*"]
+	return value, ok
+}
+
+// Topics returns every topic events can be routed to, sorted
+func (r *TopicRouter) Topics() []string {
+	seen := map[string]bool{r.defaultTopic: true}
+	topics := []string{r.defaultTopic}
+	for _, topic := range r.routes {
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go/sasl"
+	awsmskiam "github.com/segmentio/kafka-go/sasl/aws_msk_iam_v2"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// Supported values for kafka.sasl_mechanism
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismScramSHA256 = "SCRAM-SHA-256"
+	SASLMechanismScramSHA512 = "SCRAM-SHA-512"
+	SASLMechanismAWSMSKIAM   = "AWS_MSK_IAM"
+)
+
+// NewSASLMechanism builds the mechanism named by kafka.sasl_mechanism
+func NewSASLMechanism(cfg *KafkaConfig) (sasl.Mechanism, error) {
+	mechanism := strings.ToUpper(strings.TrimSpace(cfg.SASLMechanism))
+
+	switch mechanism {
+	case SASLMechanismPlain, SASLMechanismScramSHA256, SASLMechanismScramSHA512:
+		if cfg.SASLUsername == "" || cfg.SASLPassword == "" {
+			return nil, fmt.Errorf("kafka.sasl_username and kafka.sasl_password are required for %s", mechanism)
+		}
+	}
+
+	switch mechanism {
+	case SASLMechanismPlain:
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case SASLMechanismScramSHA256:
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case SASLMechanismScramSHA512:
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	case SASLMechanismAWSMSKIAM:
+		return newAWSMSKIAMMechanism()
+	default:
+		return nil, fmt.Errorf("unsupported kafka.sasl_mechanism %q (expected %s, %s, %s or %s)",
+			cfg.SASLMechanism, SASLMechanismPlain, SASLMechanismScramSHA256, SASLMechanismScramSHA512, SASLMechanismAWSMSKIAM)
+	}
+}
+
+// newAWSMSKIAMMechanism reads kafka.aws_msk_iam and loads credentials with the
+// AWS SDK's default chain: the AWS_* environment variables, the shared
+// credentials and config files, then the container or instance role
+func newAWSMSKIAMMechanism() (*awsmskiam.Mechanism, error) {
+	var options []func(*awsconfig.LoadOptions) error
+	if region := facades.Config().GetString("kafka.aws_msk_iam.region", ""); region != "" {
+		options = append(options, awsconfig.WithRegion(region))
+	}
+	if profile := facades.Config().GetString("kafka.aws_msk_iam.profile", ""); profile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(profile))
+	}
+	if file := facades.Config().GetString("kafka.aws_msk_iam.credentials_file", ""); file != "" {
+		options = append(options, awsconfig.WithSharedCredentialsFiles([]string{file}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration for AWS_MSK_IAM: %w", err)
+	}
+	if awsCfg.Region == "" {
+		return nil, errors.New("kafka.aws_msk_iam.region is required for AWS_MSK_IAM")
+	}
+	if _, err := awsCfg.Credentials.Retrieve(ctx); err != nil {
+		return nil, fmt.Errorf("no AWS credentials for AWS_MSK_IAM: %w", err)
+	}
+
+	return awsmskiam.NewMechanism(awsCfg), nil
+}
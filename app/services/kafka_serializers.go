@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Content types written to the content-type header, one per serializer
+const (
+	ContentTypeAvro     = "application/avro"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Serializer encodes envelopes into message values and back. The topic is
+// passed so schema-based serializers can resolve the "<topic>-value" subject.
+type Serializer interface {
+	ContentType() string
+	Serialize(ctx context.Context, topic string, envelope *EventEnvelope) ([]byte, error)
+	Deserialize(ctx context.Context, topic string, value []byte) (*EventEnvelope, error)
+}
+
+// NewSerializers returns the serializers available with the given registry,
+// keyed by content type. JSON is always available; Avro and Protobuf need a
+// schema registry.
+func NewSerializers(registry *SchemaRegistryClient) (map[string]Serializer, error) {
+	serializers := map[string]Serializer{ContentTypeJSON: JSONSerializer{}}
+	if registry == nil {
+		return serializers, nil
+	}
+
+	avroSerializer, err := NewAvroSerializer(registry)
+	if err != nil {
+		return nil, err
+	}
+	protobufSerializer, err := NewProtobufSerializer(registry)
+	if err != nil {
+		return nil, err
+	}
+	serializers[ContentTypeAvro] = avroSerializer
+	serializers[ContentTypeProtobuf] = protobufSerializer
+	return serializers, nil
+}
+
+// serializerContentTypes maps kafka.serializer values to content types
+var serializerContentTypes = map[string]string{
+	"json":     ContentTypeJSON,
+	"avro":     ContentTypeAvro,
+	"protobuf": ContentTypeProtobuf,
+}
+
+// getSerializers builds the serializers from kafka.schema_registry and returns
+// them with the one selected by kafka.serializer
+func getSerializers() (map[string]Serializer, Serializer, error) {
+	name := strings.ToLower(facades.Config().GetString("kafka.serializer", "json"))
+	contentType, ok := serializerContentTypes[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported kafka.serializer %q (expected json, avro or protobuf)", name)
+	}
+
+	serializers, err := NewSerializers(getSchemaRegistryClient())
+	if err != nil {
+		return nil, nil, err
+	}
+	serializer, ok := serializers[contentType]
+	if !ok {
+		return nil, nil, fmt.Errorf("kafka.serializer %q requires kafka.schema_registry.url", name)
+	}
+	return serializers, serializer, nil
+}
+
+// JSONSerializer writes envelopes as plain JSON
+type JSONSerializer struct{}
+
+func (JSONSerializer) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSONSerializer) Serialize(_ context.Context, _ string, envelope *EventEnvelope) ([]byte, error) {
+	return json.Marshal(envelope)
+}
+
+func (JSONSerializer) Deserialize(_ context.Context, _ string, value []byte) (*EventEnvelope, error) {
+	return DecodeEnvelope(value)
+}
+
+// Confluent wire format: a zero magic byte and the big-endian schema ID
+// precede the encoded value
+const (
+	wireMagicByte  = 0
+	wireHeaderSize = 5
+)
+
+// ErrInvalidWireFormat is returned for values without the schema registry framing
+var ErrInvalidWireFormat = errors.New("message value is not in schema registry wire format")
+
+// frame prefixes an encoded value with the magic byte and schema ID
+func frame(schemaID int, prefix []byte, payload []byte) []byte {
+	value := make([]byte, wireHeaderSize, wireHeaderSize+len(prefix)+len(payload))
+	value[0] = wireMagicByte
+	binary.BigEndian.PutUint32(value[1:], uint32(schemaID))
+	value = append(value, prefix...)
+	return append(value, payload...)
+}
+
+// unframe splits a framed value into its schema ID and encoded value
+func unframe(value []byte) (int, []byte, error) {
+	if len(value) < wireHeaderSize || value[0] != wireMagicByte {
+		return 0, nil, ErrInvalidWireFormat
+	}
+	return int(binary.BigEndian.Uint32(value[1:wireHeaderSize])), value[wireHeaderSize:], nil
+}
+
+// valueSubject returns the registry subject for a topic's values (TopicNameStrategy)
+func valueSubject(topic string) string {
+	return topic + "-value"
+}
+
+// avroEnvelopeSchema describes the envelope. The data stays JSON so one schema
+// covers every event type.
+const avroEnvelopeSchema = `{
+  "type": "record",
+  "name": "EventEnvelope",
+  "namespace": "activityservice.events",
+  "fields": [
+    {"name": "schema_version", "type": "int"},
+    {"name": "event_id", "type": "string"},
+    {"name": "event_type", "type": "string"},
+    {"name": "source", "type": "string"},
+    {"name": "occurred_at", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+    {"name": "correlation_id", "type": ["null", "string"], "default": null},
+    {"name": "traceparent", "type": ["null", "string"], "default": null},
+    {"name": "subject", "type": ["null", "string"], "default": null},
+    {"name": "data", "type": "string"}
+  ]
+}`
+
+// avroEnvelope is the Go shape of avroEnvelopeSchema
+type avroEnvelope struct {
+	SchemaVersion int       `avro:"schema_version"`
+	EventID       string    `avro:"event_id"`
+	EventType     string    `avro:"event_type"`
+	Source        string    `avro:"source"`
+	OccurredAt    time.Time `avro:"occurred_at"`
+	CorrelationID *string   `avro:"correlation_id"`
+	TraceParent   *string   `avro:"traceparent"`
+	Subject       *string   `avro:"subject"`
+	Data          string    `avro:"data"`
+}
+
+// AvroSerializer writes envelopes as Avro in the schema registry wire format
+type AvroSerializer struct {
+	registry *SchemaRegistryClient
+	schema   avro.Schema
+}
+
+// NewAvroSerializer creates an Avro serializer that registers its schema with registry
+func NewAvroSerializer(registry *SchemaRegistryClient) (*AvroSerializer, error) {
+	schema, err := avro.Parse(avroEnvelopeSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &AvroSerializer{registry: registry, schema: schema}, nil
+}
+
+func (s *AvroSerializer) ContentType() string {
+	return ContentTypeAvro
+}
+
+func (s *AvroSerializer) Serialize(ctx context.Context, topic string, envelope *EventEnvelope) ([]byte, error) {
+	schemaID, err := s.registry.SchemaID(ctx, valueSubject(topic), SchemaTypeAvro, s.schema.String())
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := avro.Marshal(s.schema, avroEnvelope{
+		SchemaVersion: envelope.SchemaVersion,
+		EventID:       envelope.EventID,
+		EventType:     envelope.EventType,
+		Source:        envelope.Source,
+		OccurredAt:    envelope.OccurredAt,
+		CorrelationID: optionalString(envelope.CorrelationID),
+		TraceParent:   optionalString(envelope.TraceParent),
+		Subject:       optionalString(envelope.Subject),
+		Data:          string(envelope.Data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frame(schemaID, nil, payload), nil
+}
+
+// Deserialize decodes with the writer's schema from the registry, so values
+// written with an older or newer envelope schema are read by field name
+func (s *AvroSerializer) Deserialize(ctx context.Context, _ string, value []byte) (*EventEnvelope, error) {
+	schemaID, payload, err := unframe(value)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := s.registry.Schema(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+	if registered.SchemaType != SchemaTypeAvro {
+		return nil, fmt.Errorf("schema %d is %s, not %s", schemaID, registered.SchemaType, SchemaTypeAvro)
+	}
+	writerSchema, err := avro.Parse(registered.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded avroEnvelope
+	if err := avro.Unmarshal(writerSchema, payload, &decoded); err != nil {
+		return nil, err
+	}
+
+	return validateEnvelope(&EventEnvelope{
+		SchemaVersion: decoded.SchemaVersion,
+		EventID:       decoded.EventID,
+		EventType:     decoded.EventType,
+		Source:        decoded.Source,
+		OccurredAt:    decoded.OccurredAt.UTC(),
+		CorrelationID: derefString(decoded.CorrelationID),
+		TraceParent:   derefString(decoded.TraceParent),
+		Subject:       derefString(decoded.Subject),
+		Data:          json.RawMessage(decoded.Data),
+	})
+}
+
+// protobufEnvelopeFields lists the envelope fields in field number order. The
+// message descriptor and the schema registered with the registry are both
+// built from it. As with Avro, the data stays JSON.
+var protobufEnvelopeFields = []struct {
+	name string
+	kind descriptorpb.FieldDescriptorProto_Type
+}{
+	{"schema_version", descriptorpb.FieldDescriptorProto_TYPE_INT32},
+	{"event_id", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"event_type", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"source", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"occurred_at", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"correlation_id", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"traceparent", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"subject", descriptorpb.FieldDescriptorProto_TYPE_STRING},
+	{"data", descriptorpb.FieldDescriptorProto_TYPE_BYTES},
+}
+
+// protobufTypeNames maps field kinds to their .proto names
+var protobufTypeNames = map[descriptorpb.FieldDescriptorProto_Type]string{
+	descriptorpb.FieldDescriptorProto_TYPE_INT32:  "int32",
+	descriptorpb.FieldDescriptorProto_TYPE_STRING: "string",
+	descriptorpb.FieldDescriptorProto_TYPE_BYTES:  "bytes",
+}
+
+// ProtobufSerializer writes envelopes as Protobuf in the schema registry wire format
+type ProtobufSerializer struct {
+	registry   *SchemaRegistryClient
+	descriptor protoreflect.MessageDescriptor
+	schema     string
+}
+
+// NewProtobufSerializer creates a Protobuf serializer that registers its schema with registry
+func NewProtobufSerializer(registry *SchemaRegistryClient) (*ProtobufSerializer, error) {
+	message := &descriptorpb.DescriptorProto{Name: proto.String("EventEnvelope")}
+	var schema strings.Builder
+	schema.WriteString("syntax = \"proto3\";\n\npackage activityservice.events;\n\nmessage EventEnvelope {\n")
+	for i, field := range protobufEnvelopeFields {
+		number := int32(i + 1)
+		message.Field = append(message.Field, &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(field.name),
+			JsonName: proto.String(field.name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     field.kind.Enum(),
+		})
+		fmt.Fprintf(&schema, "  %s %s = %d;\n", protobufTypeNames[field.kind], field.name, number)
+	}
+	schema.WriteString("}\n")
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String("activityservice/events/event_envelope.proto"),
+		Package:     proto.String("activityservice.events"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{message},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtobufSerializer{
+		registry:   registry,
+		descriptor: file.Messages().Get(0),
+		schema:     schema.String(),
+	}, nil
+}
+
+func (s *ProtobufSerializer) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+// Schema returns the .proto definition registered for the envelope
+func (s *ProtobufSerializer) Schema() string {
+	return s.schema
+}
+
+func (s *ProtobufSerializer) Serialize(ctx context.Context, topic string, envelope *EventEnvelope) ([]byte, error) {
+	schemaID, err := s.registry.SchemaID(ctx, valueSubject(topic), SchemaTypeProtobuf, s.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	message := dynamicpb.NewMessage(s.descriptor)
+	fields := s.descriptor.Fields()
+	message.Set(fields.ByName("schema_version"), protoreflect.ValueOfInt32(int32(envelope.SchemaVersion)))
+	message.Set(fields.ByName("event_id"), protoreflect.ValueOfString(envelope.EventID))
+	message.Set(fields.ByName("event_type"), protoreflect.ValueOfString(envelope.EventType))
+	message.Set(fields.ByName("source"), protoreflect.ValueOfString(envelope.Source))
+	message.Set(fields.ByName("occurred_at"), protoreflect.ValueOfString(envelope.OccurredAt.UTC().Format(time.RFC3339Nano)))
+	message.Set(fields.ByName("correlation_id"), protoreflect.ValueOfString(envelope.CorrelationID))
+	message.Set(fields.ByName("traceparent"), protoreflect.ValueOfString(envelope.TraceParent))
+	message.Set(fields.ByName("subject"), protoreflect.ValueOfString(envelope.Subject))
+	message.Set(fields.ByName("data"), protoreflect.ValueOfBytes(envelope.Data))
+
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	// The message index list identifies the message within the schema; a
+	// single zero stands for the first message
+	return frame(schemaID, protowire.AppendVarint(nil, 0), payload), nil
+}
+
+// Deserialize decodes with the local descriptor. Protobuf reads values by
+// field number, so the writer's schema is not needed.
+func (s *ProtobufSerializer) Deserialize(_ context.Context, _ string, value []byte) (*EventEnvelope, error) {
+	_, payload, err := unframe(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the message index list: a count followed by that many indexes, or a
+	// single zero for the first message
+	count, n := protowire.ConsumeVarint(payload)
+	if n < 0 {
+		return nil, protowire.ParseError(n)
+	}
+	payload = payload[n:]
+	for i := int64(0); i < protowire.DecodeZigZag(count); i++ {
+		if _, n = protowire.ConsumeVarint(payload); n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		payload = payload[n:]
+	}
+
+	message := dynamicpb.NewMessage(s.descriptor)
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, err
+	}
+
+	fields := s.descriptor.Fields()
+	envelope := &EventEnvelope{
+		SchemaVersion: int(message.Get(fields.ByName("schema_version")).Int()),
+		EventID:       message.Get(fields.ByName("event_id")).String(),
+		EventType:     message.Get(fields.ByName("event_type")).String(),
+		Source:        message.Get(fields.ByName("source")).String(),
+		CorrelationID: message.Get(fields.ByName("correlation_id")).String(),
+		TraceParent:   message.Get(fields.ByName("traceparent")).String(),
+		Subject:       message.Get(fields.ByName("subject")).String(),
+		Data:          json.RawMessage(message.Get(fields.ByName("data")).Bytes()),
+	}
+	if occurredAt := message.Get(fields.ByName("occurred_at")).String(); occurredAt != "" {
+		if envelope.OccurredAt, err = time.Parse(time.RFC3339Nano, occurredAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return validateEnvelope(envelope)
+}
+
+// validateEnvelope applies the checks DecodeEnvelope makes to JSON envelopes
+func validateEnvelope(envelope *EventEnvelope) (*EventEnvelope, error) {
+	if envelope.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, envelope.SchemaVersion)
+	}
+	if envelope.EventType == "" {
+		return nil, errors.New("event envelope has no event_type")
+	}
+	return envelope, nil
+}
+
+// optionalString maps "" to nil for nullable Avro fields
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// derefString maps nil to ""
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
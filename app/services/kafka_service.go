@@ -2,42 +2,76 @@ package services
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/goravel/framework/facades"
 	"github.com/segmentio/kafka-go"
-	"github.com/segmentio/kafka-go/sasl/scram"
 )
 
 // KafkaConfig represents Kafka configuration
 type KafkaConfig struct {
-	Profile              string
-	BootstrapServers     string
-	SecurityProtocol     string
-	SASLMechanism        string
-	SASLUsername         string
-	SASLPassword         string
-	ClientID             string
-	Acks                 int
-	Retries              int
-	RetryBackoffMs       int
-	MaxInFlightRequests  int
-	ActivityEventsTopic  string
-	ConsumerGroupID      string
-	AutoOffsetReset      string
-	EnableAutoCommit     bool
-	AutoCommitIntervalMs int
+	Profile                string
+	BootstrapServers       string
+	SecurityProtocol       string
+	SASLMechanism          string
+	SASLUsername           string
+	SASLPassword           string
+	ClientID               string
+	Acks                   int
+	Retries                int
+	RetryBackoffMs         int
+	MaxConcurrentPublishes int
+	RequestTimeoutMs       int
+	RetryBackoffMaxMs      int
+	BatchSize              int
+	BatchBytes             int
+	BatchTimeoutMs         int
+	Compression            string
+	Partitioner            string
+	Async                  bool
+	ActivityEventsTopic    string
+	ConsumerTopics         []string
+	ConsumerGroupID        string
+	AutoOffsetReset        string
+	EnableAutoCommit       bool
+	AutoCommitIntervalMs   int
+	ConsumerWorkers        int
+	ConsumerMaxInFlight    int
+	ShutdownTimeoutMs      int
 }
 
 type KafkaService struct {
-	producer *kafka.Writer
-	reader   *kafka.Reader
-	enabled  bool
-	config   *KafkaConfig
-	mu       sync.RWMutex
+	producer      *kafka.Writer
+	asyncProducer *kafka.Writer
+	transport     *kafka.Transport
+	dialer        *kafka.Dialer
+	publishSlots  chan struct{}
+	reader        *kafka.Reader
+	readerConfig  kafka.ReaderConfig
+	enabled       bool
+	config        *KafkaConfig
+	router        *TopicRouter
+	partitionKeys *PartitionKeyResolver
+	serializer    Serializer
+	serializers   map[string]Serializer
+	retryPolicy   *RetryPolicy
+	processed     *ProcessedEventStore
+	// ready is closed once Kafka is enabled, at startup or after reconnecting
+	ready           chan struct{}
+	reconnecting    bool
+	reconnectPolicy *ReconnectPolicy
+	metrics         *kafkaMetrics
+	sampling        sync.Once
+	status          kafkaStatus
+	// stopped is cancelled by Close to stop reconnecting and flushing
+	stopped context.Context
+	stop    context.CancelFunc
+	mu      sync.RWMutex
 }
 
 var (
@@ -45,45 +79,37 @@ var (
 	once                 sync.Once
 )
 
+// MessageHandler handles a raw Kafka message
+type MessageHandler func(ctx context.Context, message *kafka.Message) error
+
+// ErrKafkaDisabled is returned when an event must be delivered but Kafka is unavailable
+var ErrKafkaDisabled = errors.New("kafka service is disabled")
+
 // GetKafkaService returns a singleton instance of KafkaService
 func GetKafkaService() *KafkaService {
 	once.Do(func() {
 		kafkaServiceInstance = &KafkaService{
-			enabled: true,
+			enabled:         true,
+			retryPolicy:     getRetryPolicy(),
+			processed:       NewProcessedEventStore(),
+			ready:           make(chan struct{}),
+			reconnectPolicy: getReconnectPolicy(),
 		}
+		kafkaServiceInstance.stopped, kafkaServiceInstance.stop = context.WithCancel(context.Background())
+		kafkaServiceInstance.metrics = newKafkaMetrics(kafkaServiceInstance)
 		kafkaServiceInstance.initialize()
 	})
 	return kafkaServiceInstance
 }
 
-// getKafkaConfig retrieves Kafka configuration from environment
-func getKafkaConfig() *KafkaConfig {
-	profile := facades.Config().GetString("kafka.profile", "local")
-
-	// Define Kafka profiles
-	profiles := map[string]*KafkaConfig{
-		"local": {
-			Profile:          "local",
-			BootstrapServers: "kafka:29092",
-			SecurityProtocol: "PLAINTEXT",
-			SASLMechanism:    "",
-			SASLUsername:     "",
-			SASLPassword:     "",
-		},
-		"aws_msk": {
-			Profile:          "aws_msk",
-			BootstrapServers: facades.Config().GetString("kafka.bootstrap_servers", "localhost:9092"),
-			SecurityProtocol: "SASL_SSL",
-			SASLMechanism:    "SCRAM-SHA-512",
-			SASLUsername:     facades.Config().GetString("kafka.sasl_username", ""),
-			SASLPassword:     facades.Config().GetString("kafka.sasl_password", ""),
-		},
-	}
-
-	cfg := profiles[profile]
-	if cfg == nil {
-		cfg = profiles["local"]
-	}
+// LoadKafkaConfig builds the Kafka configuration. Connection settings come
+// from the kafka.profiles entry named by kafka.profile; the top-level
+// bootstrap_servers, security_protocol and sasl_* settings override them when
+// set. An unknown or incomplete profile is reported as an error; the returned
+// config then still holds the non-connection settings.
+func LoadKafkaConfig() (*KafkaConfig, error) {
+	cfg := &KafkaConfig{Profile: facades.Config().GetString("kafka.profile", "local")}
+	profileErr := applyKafkaProfile(cfg)
 
 	// Override with individual settings if specified
 	if bs := facades.Config().GetString("kafka.bootstrap_servers", ""); bs != "" {
@@ -92,167 +118,325 @@ func getKafkaConfig() *KafkaConfig {
 	if sp := facades.Config().GetString("kafka.security_protocol", ""); sp != "" {
 		cfg.SecurityProtocol = sp
 	}
+	if mechanism := facades.Config().GetString("kafka.sasl_mechanism", ""); mechanism != "" {
+		cfg.SASLMechanism = mechanism
+	}
+	if username := facades.Config().GetString("kafka.sasl_username", ""); username != "" {
+		cfg.SASLUsername = username
+	}
+	if password := facades.Config().GetString("kafka.sasl_password", ""); password != "" {
+		cfg.SASLPassword = password
+	}
 
 	cfg.ClientID = facades.Config().GetString("kafka.client_id", "activity-service")
 	cfg.Acks = facades.Config().GetInt("kafka.acks", -1)
 	cfg.Retries = facades.Config().GetInt("kafka.retries", 3)
 	cfg.RetryBackoffMs = facades.Config().GetInt("kafka.retry_backoff_ms", 100)
-	cfg.MaxInFlightRequests = facades.Config().GetInt("kafka.max_in_flight_requests", 5)
+	cfg.MaxConcurrentPublishes = facades.Config().GetInt("kafka.max_concurrent_publishes", 5)
+	cfg.RequestTimeoutMs = facades.Config().GetInt("kafka.request_timeout_ms", 30000)
+	cfg.RetryBackoffMaxMs = facades.Config().GetInt("kafka.retry_backoff_max_ms", 1000)
+	cfg.BatchSize = facades.Config().GetInt("kafka.batch_size", 100)
+	cfg.BatchBytes = facades.Config().GetInt("kafka.batch_bytes", 1048576)
+	cfg.BatchTimeoutMs = facades.Config().GetInt("kafka.batch_timeout_ms", 10)
+	cfg.Compression = facades.Config().GetString("kafka.compression", "none")
+	cfg.Partitioner = facades.Config().GetString("kafka.partitioner", "murmur2")
+	cfg.Async = facades.Config().GetBool("kafka.async", false)
 	cfg.ActivityEventsTopic = facades.Config().GetString("kafka.activity_events_topic", "activity-events")
+	for _, topic := range strings.Split(facades.Config().GetString("kafka.consumer_topics", ""), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			cfg.ConsumerTopics = append(cfg.ConsumerTopics, topic)
+		}
+	}
 	cfg.ConsumerGroupID = facades.Config().GetString("kafka.consumer_group_id", "activity-service-consumers")
 	cfg.AutoOffsetReset = facades.Config().GetString("kafka.auto_offset_reset", "earliest")
 	cfg.EnableAutoCommit = facades.Config().GetBool("kafka.enable_auto_commit", true)
 	cfg.AutoCommitIntervalMs = facades.Config().GetInt("kafka.auto_commit_interval_ms", 1000)
+	cfg.ConsumerWorkers = facades.Config().GetInt("kafka.consumer_workers", 4)
+	cfg.ConsumerMaxInFlight = facades.Config().GetInt("kafka.consumer_max_in_flight", 100)
+	cfg.ShutdownTimeoutMs = facades.Config().GetInt("kafka.consumer_shutdown_timeout_ms", 25000)
 
-	return cfg
+	if profileErr != nil {
+		return cfg, profileErr
+	}
+	if len(cfg.Brokers()) == 0 {
+		return cfg, fmt.Errorf("kafka profile %q has no bootstrap_servers", cfg.Profile)
+	}
+	return cfg, nil
 }
 
-// initialize sets up the Kafka producer and reader
-func (ks *KafkaService) initialize() {
-	ks.config = getKafkaConfig()
-	brokers := []string{ks.config.BootstrapServers}
-
-	// Create writer configuration
-	writerConfig := kafka.WriterConfig{
-		Brokers:      brokers,
-		Topic:        ks.config.ActivityEventsTopic,
-		MaxAttempts:  ks.config.Retries + 1,
-		WriteTimeout: 10 * time.Second,
-		ReadTimeout:  10 * time.Second,
-	}
-
-	// Add SASL/TLS configuration if needed
-	if ks.config.SecurityProtocol != "PLAINTEXT" {
-		dialer := &kafka.Dialer{
-			Timeout:   10 * time.Second,
-			DualStack: true,
+// applyKafkaProfile copies the connection settings of the selected profile
+func applyKafkaProfile(cfg *KafkaConfig) error {
+	profiles, _ := facades.Config().Get("kafka.profiles").(map[string]any)
+	if _, ok := profiles[cfg.Profile]; !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
 		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown kafka.profile %q (available: %s)", cfg.Profile, strings.Join(names, ", "))
+	}
 
-		// Configure SASL if credentials are provided
-		if ks.config.SASLUsername != "" && ks.config.SASLPassword != "" {
-			mechanism, err := scram.Mechanism(scram.SHA512, ks.config.SASLUsername, ks.config.SASLPassword)
-			if err != nil {
-				facades.Log().Warning("Failed to create SCRAM mechanism: " + err.Error())
-				ks.enabled = false
-				return
-			}
+	prefix := "kafka.profiles." + cfg.Profile + "."
+	cfg.BootstrapServers = facades.Config().GetString(prefix+"bootstrap_servers", "")
+	cfg.SecurityProtocol = facades.Config().GetString(prefix+"security_protocol", "PLAINTEXT")
+	cfg.SASLMechanism = facades.Config().GetString(prefix+"sasl_mechanism", "")
+	cfg.SASLUsername = facades.Config().GetString(prefix+"sasl_username", "")
+	cfg.SASLPassword = facades.Config().GetString(prefix+"sasl_password", "")
+	return nil
+}
 
-			dialer.SASLMechanism = mechanism
+// Brokers returns the comma-separated bootstrap servers as a list
+func (c *KafkaConfig) Brokers() []string {
+	var brokers []string
+	for _, broker := range strings.Split(c.BootstrapServers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
 		}
+	}
+	return brokers
+}
 
-		// Configure TLS
-		dialer.TLS = &tls.Config{
-			InsecureSkipVerify: true, // For testing; use proper certificates in production
-		}
+// StartOffset returns where a consumer group without committed offsets starts
+// reading: the oldest retained message for "earliest", new messages for "latest"
+func (c *KafkaConfig) StartOffset() (int64, error) {
+	switch strings.ToLower(c.AutoOffsetReset) {
+	case "earliest":
+		return kafka.FirstOffset, nil
+	case "latest":
+		return kafka.LastOffset, nil
+	default:
+		return 0, fmt.Errorf("unsupported kafka.auto_offset_reset %q (expected earliest or latest)", c.AutoOffsetReset)
+	}
+}
+
+// initialize sets up the Kafka producer and reader
+func (ks *KafkaService) initialize() {
+	config, err := LoadKafkaConfig()
+	ks.config = config
+	ks.router = getTopicRouter(ks.config.ActivityEventsTopic)
+	if err != nil {
+		facades.Log().Error("Invalid Kafka configuration: " + err.Error())
+		ks.enabled = false
+		return
+	}
+	brokers := ks.config.Brokers()
 
-		writerConfig.Dialer = dialer
+	// The writers and reader share one dialer carrying the TLS and SASL settings.
+	// A bad security setup disables Kafka rather than connecting insecurely.
+	dialer, err := ks.newDialer()
+	if err != nil {
+		facades.Log().Error("Invalid Kafka security configuration: " + err.Error())
+		ks.enabled = false
+		return
 	}
+	ks.dialer = dialer
+	ks.transport = newTransport(dialer)
 
-	ks.producer = kafka.NewWriter(writerConfig)
+	if ks.partitionKeys, err = getPartitionKeyResolver(); err != nil {
+		facades.Log().Error("Invalid Kafka producer configuration: " + err.Error())
+		ks.enabled = false
+		return
+	}
+	if ks.serializers, ks.serializer, err = getSerializers(); err != nil {
+		facades.Log().Error("Invalid Kafka serializer configuration: " + err.Error())
+		ks.enabled = false
+		return
+	}
 
-	// Create reader configuration
+	// Synchronous publishes wait for acknowledgement; at most
+	// max_concurrent_publishes of them are sent at once
+	if ks.producer, err = ks.newWriter(false); err != nil {
+		facades.Log().Error("Invalid Kafka producer configuration: " + err.Error())
+		ks.enabled = false
+		return
+	}
+	ks.asyncProducer, _ = ks.newWriter(true)
+	ks.publishSlots = make(chan struct{}, max(ks.config.MaxConcurrentPublishes, 1))
+
+	startOffset, err := ks.config.StartOffset()
+	if err != nil {
+		facades.Log().Error("Invalid Kafka consumer configuration: " + err.Error())
+		ks.enabled = false
+		return
+	}
+
+	// Create reader configuration. Topics are chosen when consuming starts.
 	readerConfig := kafka.ReaderConfig{
 		Brokers:       brokers,
-		Topic:         ks.config.ActivityEventsTopic,
+		Dialer:        dialer,
 		GroupID:       ks.config.ConsumerGroupID,
-		StartOffset:   kafka.LastOffset,
+		StartOffset:   startOffset,
 		MaxAttempts:   ks.config.Retries + 1,
 		QueueCapacity: 100,
 	}
 
-	// Add SASL/TLS for reader if needed
-	if ks.config.SecurityProtocol != "PLAINTEXT" {
-		dialer := &kafka.Dialer{
-			Timeout:   10 * time.Second,
-			DualStack: true,
-		}
+	// With auto commit, offsets are committed on read every auto_commit_interval_ms.
+	// Otherwise messages are committed explicitly once they have been handled.
+	if ks.config.EnableAutoCommit {
+		readerConfig.CommitInterval = time.Duration(ks.config.AutoCommitIntervalMs) * time.Millisecond
+	}
 
-		if ks.config.SASLUsername != "" && ks.config.SASLPassword != "" {
-			mechanism, err := scram.Mechanism(scram.SHA512, ks.config.SASLUsername, ks.config.SASLPassword)
-			if err != nil {
-				facades.Log().Warning("Failed to create SCRAM mechanism for reader: " + err.Error())
-				ks.enabled = false
-				return
-			}
-			dialer.SASLMechanism = mechanism
+	ks.readerConfig = readerConfig
+
+	// Check connectivity with metadata requests; nothing is produced
+	testCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	report, err := ks.CheckHealth(testCtx, ks.HealthTopics())
+	if err != nil {
+		ks.enabled = false
+		if !ks.reconnectPolicy.Enabled {
+			facades.Log().Warning("Kafka connection failed: " + err.Error() + " - events will be buffered in the outbox but not published")
+			return
 		}
 
-		dialer.TLS = &tls.Config{
-			InsecureSkipVerify: true,
+		// Keep trying in the background; events are buffered in the outbox meanwhile
+		facades.Log().Warning("Kafka connection failed: " + err.Error() + " - reconnecting in the background")
+		ks.reconnecting = true
+		go ks.reconnect()
+		return
+	}
+	close(ks.ready)
+
+	for _, topic := range report.Topics {
+		if topic.Error != "" || len(topic.LeaderlessPartitions) > 0 {
+			facades.Log().Warning("Kafka topic is not ready", map[string]interface{}{
+				"topic":                 topic.Name,
+				"error":                 topic.Error,
+				"leaderless_partitions": topic.LeaderlessPartitions,
+			})
 		}
+	}
+	facades.Log().Info("Kafka service initialized successfully with profile: " + ks.config.Profile)
+}
 
-		readerConfig.Dialer = dialer
+// newDialer builds the dialer for kafka.security_protocol: TLS is used for SSL
+// and SASL_SSL, and kafka.sasl_mechanism for SASL_PLAINTEXT and SASL_SSL
+func (ks *KafkaService) newDialer() (*kafka.Dialer, error) {
+	dialer := &kafka.Dialer{
+		ClientID:  ks.config.ClientID,
+		Timeout:   10 * time.Second,
+		DualStack: true,
 	}
 
-	ks.reader = kafka.NewReader(readerConfig)
+	protocol := strings.ToUpper(ks.config.SecurityProtocol)
+	switch protocol {
+	case "PLAINTEXT", "SSL", "SASL_PLAINTEXT", "SASL_SSL":
+	default:
+		return nil, fmt.Errorf("unsupported kafka.security_protocol %q", ks.config.SecurityProtocol)
+	}
 
-	// Test connection
-	testCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if strings.HasSuffix(protocol, "SSL") {
+		tlsConfig, err := NewTLSConfig(getTLSSettings())
+		if err != nil {
+			return nil, err
+		}
+		dialer.TLS = tlsConfig
+	}
 
-	if err := ks.testConnection(testCtx); err != nil {
-		facades.Log().Warning("Kafka connection failed: " + err.Error() + " - events will be logged but not published")
-		ks.enabled = false
-	} else {
-		facades.Log().Info("Kafka service initialized successfully with profile: " + ks.config.Profile)
+	if strings.HasPrefix(protocol, "SASL_") {
+		mechanism, err := NewSASLMechanism(ks.config)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
 	}
+
+	return dialer, nil
 }
 
-// testConnection tests the Kafka connection
-func (ks *KafkaService) testConnection(ctx context.Context) error {
-	// Try to connect and write a test message (doesn't actually send it)
-	msg := kafka.Message{
-		Key:   []byte("test"),
-		Value: []byte("test"),
+// PublishEvent publishes an event to Kafka. With kafka.async enabled it
+// returns without waiting for the brokers, as PublishEventAsync does. Events
+// that cannot be published because Kafka is down are buffered in the outbox
+// and relayed once the connection is restored.
+func (ks *KafkaService) PublishEvent(eventType string, data interface{}) error {
+	if ks.config.Async {
+		return ks.PublishEventAsync(eventType, data, nil)
 	}
 
-	// Use a timeout for connection test
-	testCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	envelope, err := NewEventEnvelope(eventType, data, "")
+	if err != nil {
+		facades.Log().Error("Failed to marshal event: " + err.Error())
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	return ks.producer.WriteMessages(testCtx, msg)
+	if err := ks.Publish(ctx, envelope); err != nil {
+		return ks.bufferEvent(envelope, err)
+	}
+	return nil
 }
 
-// PublishEvent publishes an event to Kafka
-func (ks *KafkaService) PublishEvent(eventType string, data interface{}) error {
+// Publish writes an event envelope to Kafka, returning ErrKafkaDisabled instead
+// of dropping the event when the service is disabled. The envelope must be
+// reused across retries of the same event so consumers can deduplicate it.
+func (ks *KafkaService) Publish(ctx context.Context, envelope *EventEnvelope) error {
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
 
 	if !ks.enabled {
-		facades.Log().Info("Event logged (Kafka disabled): type=" + eventType)
-		return nil
-	}
-
-	eventPayload := map[string]interface{}{
-		"event_type": eventType,
-		"event_id":   facades.Config().GetString("app.name", "activity-service"),
-		"timestamp":  time.Now().UTC().Format(time.RFC3339),
-		"data":       data,
+		return ErrKafkaDisabled
 	}
 
-	jsonData, err := json.Marshal(eventPayload)
+	msg, err := ks.message(ctx, envelope)
 	if err != nil {
-		facades.Log().Error("Failed to marshal event: " + err.Error())
 		return err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	msg := kafka.Message{
-		Key:   []byte(eventType),
-		Value: jsonData,
+	select {
+	case ks.publishSlots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+	defer func() { <-ks.publishSlots }()
 
-	if err := ks.producer.WriteMessages(ctx, msg); err != nil {
+	start := time.Now()
+	err = ks.producer.WriteMessages(ctx, msg)
+	ks.metrics.observePublish(msg.Topic, time.Since(start), err)
+	if err != nil {
 		facades.Log().Error("Failed to publish event to Kafka: " + err.Error())
 		return err
 	}
 
-	facades.Log().Info("Event published to Kafka: type=" + eventType + ", topic=" + ks.config.ActivityEventsTopic)
+	facades.Log().Info("Event published to Kafka: type=" + envelope.EventType + ", id=" + envelope.EventID + ", topic=" + msg.Topic)
 	return nil
 }
 
+// message builds the Kafka message for an envelope on its routed topic,
+// encoded with the configured serializer
+func (ks *KafkaService) message(ctx context.Context, envelope *EventEnvelope) (kafka.Message, error) {
+	topic := ks.router.Route(envelope.EventType)
+	value, err := ks.serializer.Serialize(ctx, topic, envelope)
+	if err != nil {
+		facades.Log().Error("Failed to serialize event: " + err.Error())
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     ks.partitionKeys.Key(envelope),
+		Value:   value,
+		Headers: EventHeaders(envelope, ks.serializer.ContentType()),
+	}, nil
+}
+
+// decode reads the envelope from a message with the serializer named by its
+// content-type header. Messages without the header are JSON.
+func (ks *KafkaService) decode(ctx context.Context, message *kafka.Message) (*EventEnvelope, error) {
+	contentType := headerValue(message.Headers, HeaderContentType)
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	serializer, ok := ks.serializers[contentType]
+	if !ok {
+		return nil, fmt.Errorf("no serializer for content type %q (is kafka.schema_registry.url set?)", contentType)
+	}
+	return serializer.Deserialize(ctx, message.Topic, message.Value)
+}
+
 // PublishActivityCreated publishes an activity created event
 func (ks *KafkaService) PublishActivityCreated(activity interface{}) error {
 	return ks.PublishEvent("activity.created", activity)
@@ -268,6 +452,11 @@ func (ks *KafkaService) PublishActivityDeleted(activity interface{}) error {
 	return ks.PublishEvent("activity.deleted", activity)
 }
 
+// Config returns the resolved Kafka configuration
+func (ks *KafkaService) Config() *KafkaConfig {
+	return ks.config
+}
+
 // IsEnabled returns whether Kafka is enabled
 func (ks *KafkaService) IsEnabled() bool {
 	ks.mu.RLock()
@@ -275,14 +464,24 @@ func (ks *KafkaService) IsEnabled() bool {
 	return ks.enabled
 }
 
-// Close closes the Kafka producer and reader
+// Close stops reconnecting and closes the Kafka producers and reader
 func (ks *KafkaService) Close() error {
+	if ks.stop != nil {
+		ks.stop()
+	}
+
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 
+	// Closing the async writer flushes messages that are still queued
 	var err error
+	if ks.asyncProducer != nil {
+		err = ks.asyncProducer.Close()
+	}
 	if ks.producer != nil {
-		err = ks.producer.Close()
+		if producerErr := ks.producer.Close(); producerErr != nil {
+			err = producerErr
+		}
 	}
 	if ks.reader != nil {
 		if readerErr := ks.reader.Close(); readerErr != nil {
@@ -292,38 +491,129 @@ func (ks *KafkaService) Close() error {
 	return err
 }
 
-// ConsumeMessages reads and processes messages from Kafka
-func (ks *KafkaService) ConsumeMessages(ctx context.Context, handler func(message *kafka.Message) error) error {
-	ks.mu.RLock()
-	defer ks.mu.RUnlock()
+// TopicHandlers maps each consumed topic to the handler for its messages
+type TopicHandlers map[string]MessageHandler
 
-	if !ks.enabled {
+// ConsumerTopics returns the topics consumed by default: kafka.consumer_topics
+// when set, otherwise every topic in the routing table
+func (ks *KafkaService) ConsumerTopics() []string {
+	if len(ks.config.ConsumerTopics) > 0 {
+		return ks.config.ConsumerTopics
+	}
+	return ks.router.Topics()
+}
+
+// ConsumeMessages consumes ConsumerTopics with the same handler for every topic
+func (ks *KafkaService) ConsumeMessages(ctx context.Context, handler MessageHandler) error {
+	handlers := TopicHandlers{}
+	for _, topic := range ks.ConsumerTopics() {
+		handlers[topic] = handler
+	}
+	return ks.ConsumeTopics(ctx, handlers)
+}
+
+// Consume dispatches the events on topics to EventHandlers() with ProcessEvent
+func (ks *KafkaService) Consume(ctx context.Context, topics []string) error {
+	handlers := TopicHandlers{}
+	for _, topic := range topics {
+		handlers[topic] = ks.ProcessEvent
+	}
+	return ks.ConsumeTopics(ctx, handlers)
+}
+
+// ConsumeTopics reads and processes messages from the given topics until ctx
+// is cancelled, passing each message to the handler for its topic. It then
+// stops fetching and waits up to kafka.consumer_shutdown_timeout_ms for
+// in-flight messages to be handled and committed before returning.
+func (ks *KafkaService) ConsumeTopics(ctx context.Context, handlers TopicHandlers) error {
+	if !ks.IsEnabled() {
 		facades.Log().Warning("Kafka consumer is disabled")
 		return nil
 	}
 
-	if ks.reader == nil {
-		facades.Log().Error("Kafka reader not initialized")
-		return nil
+	if len(handlers) == 0 {
+		return errors.New("no Kafka topics to consume")
+	}
+
+	topics := make([]string, 0, len(handlers))
+	for topic := range handlers {
+		topics = append(topics, topic)
 	}
+	sort.Strings(topics)
+
+	readerConfig := ks.readerConfig
+	readerConfig.GroupTopics = topics
+	reader := kafka.NewReader(readerConfig)
+	defer reader.Close()
+	defer ks.metrics.watchReader(reader)()
+
+	ks.mu.Lock()
+	ks.reader = reader
+	ks.mu.Unlock()
 
 	facades.Log().Info("Starting Kafka message consumer", map[string]interface{}{
-		"topic":    ks.config.ActivityEventsTopic,
-		"group_id": ks.config.ConsumerGroupID,
-		"brokers":  ks.config.BootstrapServers,
+		"topics":      strings.Join(topics, ","),
+		"group_id":    ks.config.ConsumerGroupID,
+		"brokers":     ks.config.BootstrapServers,
+		"auto_commit": ks.config.EnableAutoCommit,
+		"workers":     ks.config.ConsumerWorkers,
 	})
 
-	for {
-		select {
-		case <-ctx.Done():
-			facades.Log().Info("Kafka consumer shutdown requested")
-			return ctx.Err()
-		default:
+	// Handlers run on their own context so in-flight messages can finish after
+	// fetching stops; it is only cancelled once the shutdown deadline passes
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	defer cancelWork()
+
+	// Failed messages are retried from their own topics after a delay
+	var retryConsumers sync.WaitGroup
+	if ks.retryPolicy.Enabled {
+		for _, topic := range topics {
+			handler := handlers[topic]
+			for attempt := 1; attempt <= len(ks.retryPolicy.Delays); attempt++ {
+				retryConfig := ks.readerConfig
+				retryConfig.Topic = ks.retryPolicy.RetryTopic(topic, attempt)
+				retryReader := kafka.NewReader(retryConfig)
+				defer retryReader.Close()
+				defer ks.metrics.watchReader(retryReader)()
+
+				retryConsumers.Add(1)
+				go func() {
+					defer retryConsumers.Done()
+					ks.consumeRetryTopic(ctx, workCtx, retryReader, handler)
+				}()
+			}
 		}
+	}
 
-		msg, err := ks.reader.ReadMessage(ctx)
+	// Messages for different aggregates are handled in parallel; messages for
+	// the same aggregate always go to the same worker and stay in order
+	tracker := NewOffsetTracker()
+	pool := NewWorkerPool(ks.config.ConsumerWorkers, ks.config.ConsumerMaxInFlight, func(msg *kafka.Message) {
+		if err := ks.processMessage(workCtx, msg, handlers[msg.Topic]); err != nil {
+			if workCtx.Err() == nil {
+				facades.Log().Error("Error processing Kafka message: "+err.Error(), map[string]interface{}{
+					"topic":  msg.Topic,
+					"offset": msg.Offset,
+					"key":    string(msg.Key),
+				})
+			}
+			// Leave the offset uncommitted; it is redelivered after a restart
+			return
+		}
+
+		if !ks.config.EnableAutoCommit {
+			tracker.Complete(msg, func(committable *kafka.Message) {
+				ks.commitMessage(reader, committable)
+			})
+		}
+	})
+	defer ks.drain(pool, &retryConsumers, cancelWork)
+
+	for {
+		msg, err := ks.fetchMessage(ctx, reader)
 		if err != nil {
-			if err == context.Canceled || err == context.DeadlineExceeded {
+			if ctx.Err() != nil {
+				facades.Log().Info("Kafka consumer shutdown requested, draining in-flight messages")
 				return nil
 			}
 			facades.Log().Error("Error reading Kafka message: " + err.Error())
@@ -338,89 +628,141 @@ func (ks *KafkaService) ConsumeMessages(ctx context.Context, handler func(messag
 			"key":       string(msg.Key),
 		})
 
-		if err := handler(&msg); err != nil {
-			facades.Log().Error("Error processing Kafka message: "+err.Error(), map[string]interface{}{
-				"topic":  msg.Topic,
-				"offset": msg.Offset,
-				"key":    string(msg.Key),
-			})
-			// Don't return on handler error, continue processing
+		if !ks.config.EnableAutoCommit {
+			tracker.Track(&msg)
+		}
+		if err := pool.Submit(ctx, &msg); err != nil {
+			facades.Log().Info("Kafka consumer shutdown requested, draining in-flight messages")
+			return nil
 		}
 	}
 }
 
-// ProcessActivityEvent handles activity events from Kafka
-func (ks *KafkaService) ProcessActivityEvent(message *kafka.Message) error {
-	var payload map[string]interface{}
-
-	if err := json.Unmarshal(message.Value, &payload); err != nil {
-		facades.Log().Error("Failed to unmarshal activity event: " + err.Error())
-		return err
+// drain waits for queued and in-flight messages to finish once fetching has
+// stopped. After kafka.consumer_shutdown_timeout_ms it cancels the remaining
+// work; those messages stay uncommitted and are redelivered after a restart.
+func (ks *KafkaService) drain(pool *WorkerPool, retryConsumers *sync.WaitGroup, cancelWork context.CancelFunc) {
+	drained := make(chan struct{})
+	go func() {
+		pool.Close()
+		retryConsumers.Wait()
+		close(drained)
+	}()
+
+	timeout := time.Duration(ks.config.ShutdownTimeoutMs) * time.Millisecond
+	select {
+	case <-drained:
+		facades.Log().Info("Kafka consumer drained all in-flight messages")
+	case <-time.After(timeout):
+		cancelWork()
+		facades.Log().Warning("Kafka consumer shutdown timed out after " + timeout.String() + ", uncommitted messages will be redelivered")
 	}
+}
 
-	eventType, ok := payload["event_type"].(string)
-	if !ok {
-		facades.Log().Warning("Invalid event_type in activity event")
-		return nil
+// fetchMessage reads the next message. With auto commit enabled the message is
+// committed on read; otherwise it must be committed with commitMessage.
+func (ks *KafkaService) fetchMessage(ctx context.Context, reader *kafka.Reader) (kafka.Message, error) {
+	if ks.config.EnableAutoCommit {
+		return reader.ReadMessage(ctx)
 	}
+	return reader.FetchMessage(ctx)
+}
 
-	data, ok := payload["data"].(map[string]interface{})
-	if !ok {
-		facades.Log().Warning("Invalid data structure in activity event")
-		return nil
+// commitMessage commits a handled message when auto commit is disabled. It
+// uses its own context so handled messages are still committed during shutdown.
+func (ks *KafkaService) commitMessage(reader *kafka.Reader, msg *kafka.Message) {
+	if ks.config.EnableAutoCommit {
+		return
 	}
 
-	facades.Log().Info("Processing activity event", map[string]interface{}{
-		"event_type":  eventType,
-		"activity_id": data["id"],
-		"topic":       message.Topic,
-		"offset":      message.Offset,
-	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	// Route to specific handlers based on event type
-	switch eventType {
-	case "activity.created":
-		return ks.handleActivityCreated(data, payload)
-	case "activity.updated":
-		return ks.handleActivityUpdated(data, payload)
-	case "activity.deleted":
-		return ks.handleActivityDeleted(data, payload)
-	default:
-		facades.Log().Warning("Unknown activity event type: " + eventType)
+	if err := reader.CommitMessages(ctx, *msg); err != nil {
+		facades.Log().Error("Failed to commit Kafka offset: "+err.Error(), map[string]interface{}{
+			"topic":     msg.Topic,
+			"partition": msg.Partition,
+			"offset":    msg.Offset,
+		})
 	}
-
-	return nil
 }
 
-// handleActivityCreated processes activity created events
-func (ks *KafkaService) handleActivityCreated(activityData map[string]interface{}, payload map[string]interface{}) error {
-	facades.Log().Info("Handling activity created event", map[string]interface{}{
-		"activity_id":   activityData["id"],
-		"activity_name": activityData["name"],
-	})
-	// Add custom business logic here
-	// - Update search indices
-	// - Send notifications
-	// - Update analytics
-	// - Sync with external systems
-	return nil
-}
+// processMessage runs the handler and forwards failed messages for a delayed
+// retry. When auto commit is disabled, a failure that cannot be forwarded is
+// retried in place with backoff, so a message is never committed unhandled.
+func (ks *KafkaService) processMessage(ctx context.Context, msg *kafka.Message, handler MessageHandler) error {
+	backoff := time.Duration(ks.config.RetryBackoffMs) * time.Millisecond
+	for {
+		err := handler(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if err = ks.handleFailure(ctx, msg, err); err == nil || ks.config.EnableAutoCommit {
+			return err
+		}
 
-// handleActivityUpdated processes activity updated events
-func (ks *KafkaService) handleActivityUpdated(activityData map[string]interface{}, payload map[string]interface{}) error {
-	facades.Log().Info("Handling activity updated event", map[string]interface{}{
-		"activity_id":   activityData["id"],
-		"activity_name": activityData["name"],
-	})
-	// Add custom business logic here
-	return nil
+		facades.Log().Error("Kafka message could not be handled or forwarded, retrying: "+err.Error(), map[string]interface{}{
+			"topic":   msg.Topic,
+			"offset":  msg.Offset,
+			"key":     string(msg.Key),
+			"backoff": backoff.String(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
 }
 
-// handleActivityDeleted processes activity deleted events
-func (ks *KafkaService) handleActivityDeleted(activityData map[string]interface{}, payload map[string]interface{}) error {
-	facades.Log().Info("Handling activity deleted event", map[string]interface{}{
-		"activity_id": activityData["id"],
+// ProcessEvent decodes an event and dispatches it to the handlers registered
+// for its type in EventHandlers(), skipping events that were already processed
+func (ks *KafkaService) ProcessEvent(ctx context.Context, message *kafka.Message) error {
+	envelope, err := ks.decode(ctx, message)
+	if err != nil {
+		facades.Log().Error("Failed to decode event: " + err.Error())
+		return err
+	}
+
+	// Claim the event before handling it so that a message redelivered after a
+	// rebalance or retry is only handled once, even by concurrent consumers
+	trackable := ks.processed.Trackable(envelope.EventID)
+	if trackable {
+		claimed, err := ks.processed.Claim(envelope.EventID, envelope.EventType)
+		if err != nil {
+			facades.Log().Error("Failed to claim event: " + err.Error())
+			return err
+		}
+		if !claimed {
+			facades.Log().Info("Skipping already processed event", map[string]interface{}{
+				"event_type": envelope.EventType,
+				"event_id":   envelope.EventID,
+				"offset":     message.Offset,
+			})
+			return nil
+		}
+	}
+
+	facades.Log().Info("Processing event", map[string]interface{}{
+		"event_type":     envelope.EventType,
+		"event_id":       envelope.EventID,
+		"schema_version": envelope.SchemaVersion,
+		"subject":        envelope.Subject,
+		"correlation_id": envelope.CorrelationID,
+		"topic":          message.Topic,
+		"offset":         message.Offset,
 	})
-	// Add custom business logic here
+
+	if err := EventHandlers().Dispatch(ctx, &Event{EventEnvelope: envelope, Message: message}); err != nil {
+		// Give the claim back so the retry of this message is not skipped
+		if trackable {
+			if releaseErr := ks.processed.Release(envelope.EventID); releaseErr != nil {
+				facades.Log().Error("Failed to release event claim: " + releaseErr.Error())
+			}
+		}
+		return err
+	}
 	return nil
 }
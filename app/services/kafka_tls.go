@@ -0,0 +1,121 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/goravel/framework/facades"
+	"github.com/youmark/pkcs8"
+)
+
+// TLSSettings holds the certificate settings from kafka.ssl
+type TLSSettings struct {
+	CALocation          string
+	CertificateLocation string
+	KeyLocation         string
+	KeyPassword         string
+	ServerName          string
+}
+
+// getTLSSettings reads the kafka.ssl block
+func getTLSSettings() TLSSettings {
+	return TLSSettings{
+		CALocation:          facades.Config().GetString("kafka.ssl.ca_location", ""),
+		CertificateLocation: facades.Config().GetString("kafka.ssl.certificate_location", ""),
+		KeyLocation:         facades.Config().GetString("kafka.ssl.key_location", ""),
+		KeyPassword:         facades.Config().GetString("kafka.ssl.key_password", ""),
+		ServerName:          facades.Config().GetString("kafka.ssl.server_name", ""),
+	}
+}
+
+// NewTLSConfig builds a verifying TLS configuration. Broker certificates are
+// checked against the CA bundle (or the system roots when none is set) and
+// the broker host name, or ServerName when set. A client certificate is
+// presented when both a certificate and a key are configured; the key may be
+// an encrypted PKCS#8 or legacy encrypted PEM key protected by KeyPassword.
+func NewTLSConfig(settings TLSSettings) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: settings.ServerName,
+	}
+
+	if settings.CALocation != "" {
+		caPEM, err := os.ReadFile(settings.CALocation)
+		if err != nil {
+			return nil, fmt.Errorf("kafka.ssl.ca_location: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("kafka.ssl.ca_location: no PEM certificates found in %s", settings.CALocation)
+		}
+		config.RootCAs = pool
+	}
+
+	if settings.CertificateLocation == "" && settings.KeyLocation == "" {
+		return config, nil
+	}
+	if settings.CertificateLocation == "" || settings.KeyLocation == "" {
+		return nil, errors.New("kafka.ssl.certificate_location and kafka.ssl.key_location must be set together")
+	}
+
+	certPEM, err := os.ReadFile(settings.CertificateLocation)
+	if err != nil {
+		return nil, fmt.Errorf("kafka.ssl.certificate_location: %w", err)
+	}
+	keyPEM, err := os.ReadFile(settings.KeyLocation)
+	if err != nil {
+		return nil, fmt.Errorf("kafka.ssl.key_location: %w", err)
+	}
+	if keyPEM, err = decryptKeyPEM(keyPEM, settings.KeyPassword); err != nil {
+		return nil, fmt.Errorf("kafka.ssl.key_location: %w", err)
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("kafka.ssl client certificate: %w", err)
+	}
+	config.Certificates = []tls.Certificate{certificate}
+
+	return config, nil
+}
+
+// decryptKeyPEM returns the private key as unencrypted PEM. Unencrypted keys
+// are returned unchanged.
+func decryptKeyPEM(keyPEM []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("no PEM private key found")
+	}
+
+	switch {
+	case block.Type == "ENCRYPTED PRIVATE KEY":
+		if password == "" {
+			return nil, errors.New("private key is encrypted but kafka.ssl.key_password is empty")
+		}
+		key, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(password))
+		if err != nil {
+			return nil, fmt.Errorf("decrypting private key: %w", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	case x509.IsEncryptedPEMBlock(block):
+		// Legacy "Proc-Type: 4,ENCRYPTED" keys, still produced by older tooling
+		if password == "" {
+			return nil, errors.New("private key is encrypted but kafka.ssl.key_password is empty")
+		}
+		der, err := x509.DecryptPEMBlock(block, []byte(password))
+		if err != nil {
+			return nil, fmt.Errorf("decrypting private key: %w", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+	default:
+		return keyPEM, nil
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// WorkerPool processes messages concurrently while keeping messages with the
+// same ordering key on the same worker, so they are handled in order
+type WorkerPool struct {
+	queues   []chan *kafka.Message
+	inFlight chan struct{}
+	process  func(msg *kafka.Message)
+	wg       sync.WaitGroup
+}
+
+// NewWorkerPool starts workers goroutines with at most maxInFlight messages
+// queued or being handled at once
+func NewWorkerPool(workers int, maxInFlight int, process func(msg *kafka.Message)) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if maxInFlight < workers {
+		maxInFlight = workers
+	}
+
+	pool := &WorkerPool{
+		queues:   make([]chan *kafka.Message, workers),
+		inFlight: make(chan struct{}, maxInFlight),
+		process:  process,
+	}
+	for i := range pool.queues {
+		pool.queues[i] = make(chan *kafka.Message, maxInFlight)
+		pool.wg.Add(1)
+		go pool.work(pool.queues[i])
+	}
+
+	return pool
+}
+
+// Submit queues a message on the worker that owns its ordering key, blocking
+// while the pool is at its in-flight limit
+func (p *WorkerPool) Submit(ctx context.Context, msg *kafka.Message) error {
+	select {
+	case p.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	hash := fnv.New32a()
+	hash.Write(OrderingKey(msg))
+	p.queues[hash.Sum32()%uint32(len(p.queues))] <- msg
+	return nil
+}
+
+// Close stops accepting messages and waits for queued messages to be handled
+func (p *WorkerPool) Close() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) work(queue chan *kafka.Message) {
+	defer p.wg.Done()
+	for msg := range queue {
+		p.process(msg)
+		<-p.inFlight
+	}
+}
+
+// OrderingKey returns the aggregate a message belongs to. The subject header
+// is preferred so that all events for one entity share a worker regardless of
+// the message key; otherwise the message key is used. The value is never
+// decoded, so the key works for every serializer.
+func OrderingKey(msg *kafka.Message) []byte {
+	if subject := headerValue(msg.Headers, HeaderSubject); subject != "" {
+		return []byte(subject)
+	}
+	return msg.Key
+}
+
+// OffsetTracker commits offsets only once every earlier message in the same
+// partition has been handled, since workers finish out of order
+type OffsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// partitionOffsets holds the fetched offsets of one partition that are not
+// committed yet. It is dropped once they are all handled, so partitions
+// revoked in a rebalance leave nothing behind after their last message.
+type partitionOffsets struct {
+	pending []int64
+	done    map[int64]bool
+}
+
+func NewOffsetTracker() *OffsetTracker {
+	return &OffsetTracker{partitions: map[topicPartition]*partitionOffsets{}}
+}
+
+// Track registers a fetched message; messages must be tracked in fetch order.
+// An offset at or below one already tracked means the partition was assigned
+// again after a rebalance and is fetched from its committed offset, so the
+// state left from the earlier assignment is dropped.
+func (t *OffsetTracker) Track(msg *kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tp := topicPartition{msg.Topic, msg.Partition}
+	offsets := t.partitions[tp]
+	if offsets == nil || msg.Offset <= offsets.pending[len(offsets.pending)-1] {
+		offsets = &partitionOffsets{done: map[int64]bool{}}
+		t.partitions[tp] = offsets
+	}
+	offsets.pending = append(offsets.pending, msg.Offset)
+}
+
+// Complete marks a message as handled and calls commit with the highest
+// offset in its partition that has no unhandled messages before it. Commits
+// happen under the tracker lock so offsets never move backwards. Messages
+// whose partition state was dropped are ignored.
+func (t *OffsetTracker) Complete(msg *kafka.Message, commit func(msg *kafka.Message)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tp := topicPartition{msg.Topic, msg.Partition}
+	offsets := t.partitions[tp]
+	if offsets == nil || msg.Offset < offsets.pending[0] || msg.Offset > offsets.pending[len(offsets.pending)-1] {
+		return
+	}
+	offsets.done[msg.Offset] = true
+
+	committable := int64(-1)
+	for len(offsets.pending) > 0 && offsets.done[offsets.pending[0]] {
+		committable = offsets.pending[0]
+		delete(offsets.done, offsets.pending[0])
+		offsets.pending = offsets.pending[1:]
+	}
+	if len(offsets.pending) == 0 {
+		delete(t.partitions, tp)
+	}
+
+	if committable >= 0 {
+		commit(&kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: committable})
+	}
+}
+
+// Partitions returns the number of partitions with messages awaiting a commit
+func (t *OffsetTracker) Partitions() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.partitions)
+}
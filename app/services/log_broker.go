@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// LogBroker writes published events to the application log and discards
+// them. It suits environments without Kafka where events only need to be seen.
+type LogBroker struct {
+	router *TopicRouter
+}
+
+// NewLogBroker creates a log broker that reports the topic each event would be routed to
+func NewLogBroker() *LogBroker {
+	return &LogBroker{router: getTopicRouter(facades.Config().GetString("kafka.activity_events_topic", "activity-events"))}
+}
+
+// Publish logs an envelope
+func (b *LogBroker) Publish(ctx context.Context, envelope *EventEnvelope) error {
+	facades.Log().Info("Event published to log broker", map[string]interface{}{
+		"event_type":     envelope.EventType,
+		"event_id":       envelope.EventID,
+		"subject":        envelope.Subject,
+		"correlation_id": envelope.CorrelationID,
+		"topic":          b.router.Route(envelope.EventType),
+		"occurred_at":    envelope.OccurredAt.Format(time.RFC3339Nano),
+		"data":           string(envelope.Data),
+	})
+	return nil
+}
+
+// PublishEvent wraps data in a new envelope and logs it
+func (b *LogBroker) PublishEvent(eventType string, data interface{}) error {
+	envelope, err := NewEventEnvelope(eventType, data, "")
+	if err != nil {
+		return err
+	}
+	return b.Publish(context.Background(), envelope)
+}
+
+// IsEnabled always returns true
+func (b *LogBroker) IsEnabled() bool {
+	return true
+}
+
+// ConsumerTopics returns no topics; there is nothing to consume
+func (b *LogBroker) ConsumerTopics() []string {
+	return nil
+}
+
+// WaitForConnection returns immediately
+func (b *LogBroker) WaitForConnection(ctx context.Context) error {
+	return nil
+}
+
+// Consume fails because logged events are not kept
+func (b *LogBroker) Consume(ctx context.Context, topics []string) error {
+	return errors.New("the log broker does not keep events to consume")
+}
+
+// Close does nothing
+func (b *LogBroker) Close() error {
+	return nil
+}
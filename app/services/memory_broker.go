@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+)
+
+// PublishedEvent is an event held by the memory broker
+type PublishedEvent struct {
+	*EventEnvelope
+	Topic string
+}
+
+// MemoryBroker keeps published events in memory and delivers them to the
+// registered handlers in the same process. It needs no brokers, so tests can
+// assert exactly which events were published.
+type MemoryBroker struct {
+	router         *TopicRouter
+	partitionKeys  *PartitionKeyResolver
+	consumerTopics []string
+	mu             sync.Mutex
+	events         []PublishedEvent
+	// generation counts resets, so consumers start over at the first event
+	generation int
+	// published is closed and replaced whenever an event is added or the
+	// events are reset, waking consumers
+	published chan struct{}
+}
+
+// NewMemoryBroker creates an empty memory broker that routes events like the Kafka service
+func NewMemoryBroker() *MemoryBroker {
+	config, _ := LoadKafkaConfig()
+	partitionKeys, err := getPartitionKeyResolver()
+	if err != nil {
+		partitionKeys, _ = NewPartitionKeyResolver(nil)
+	}
+	return &MemoryBroker{
+		router:         getTopicRouter(config.ActivityEventsTopic),
+		partitionKeys:  partitionKeys,
+		consumerTopics: config.ConsumerTopics,
+		published:      make(chan struct{}),
+	}
+}
+
+// Publish stores an envelope on its routed topic
+func (b *MemoryBroker) Publish(ctx context.Context, envelope *EventEnvelope) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(b.events, PublishedEvent{EventEnvelope: envelope, Topic: b.router.Route(envelope.EventType)})
+	close(b.published)
+	b.published = make(chan struct{})
+	return nil
+}
+
+// PublishEvent wraps data in a new envelope and stores it
+func (b *MemoryBroker) PublishEvent(eventType string, data interface{}) error {
+	envelope, err := NewEventEnvelope(eventType, data, "")
+	if err != nil {
+		return err
+	}
+	return b.Publish(context.Background(), envelope)
+}
+
+// IsEnabled always returns true
+func (b *MemoryBroker) IsEnabled() bool {
+	return true
+}
+
+// Published returns every event published so far, in order
+func (b *MemoryBroker) Published() []PublishedEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Clone(b.events)
+}
+
+// PublishedOfType returns the published events of one type, in order
+func (b *MemoryBroker) PublishedOfType(eventType string) []PublishedEvent {
+	var events []PublishedEvent
+	for _, event := range b.Published() {
+		if event.EventType == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Reset forgets every published event. Running consumers carry on with the
+// events published after it.
+func (b *MemoryBroker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = nil
+	b.generation++
+	close(b.published)
+	b.published = make(chan struct{})
+}
+
+// ConsumerTopics returns kafka.consumer_topics when set, otherwise every routed topic
+func (b *MemoryBroker) ConsumerTopics() []string {
+	if len(b.consumerTopics) > 0 {
+		return b.consumerTopics
+	}
+	return b.router.Topics()
+}
+
+// WaitForConnection returns immediately
+func (b *MemoryBroker) WaitForConnection(ctx context.Context) error {
+	return nil
+}
+
+// Consume dispatches the events on topics to EventHandlers(), starting with
+// those already published, until ctx is cancelled. Handler errors are logged;
+// failed events are not retried.
+func (b *MemoryBroker) Consume(ctx context.Context, topics []string) error {
+	for next, generation := 0, 0; ; {
+		b.mu.Lock()
+		if generation != b.generation {
+			next, generation = 0, b.generation
+		}
+		pending := b.events[next:]
+		published := b.published
+		b.mu.Unlock()
+
+		for _, event := range pending {
+			next++
+			if slices.Contains(topics, event.Topic) {
+				b.dispatch(ctx, event, int64(next-1))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-published:
+		}
+	}
+}
+
+// dispatch runs the handlers for an event with a message shaped like the Kafka one
+func (b *MemoryBroker) dispatch(ctx context.Context, event PublishedEvent, offset int64) {
+	value, err := json.Marshal(event.EventEnvelope)
+	if err != nil {
+		facades.Log().Error("Failed to encode event: " + err.Error())
+		return
+	}
+	message := &kafka.Message{
+		Topic:   event.Topic,
+		Offset:  offset,
+		Key:     b.partitionKeys.Key(event.EventEnvelope),
+		Value:   value,
+		Headers: EventHeaders(event.EventEnvelope, ContentTypeJSON),
+	}
+
+	if err := EventHandlers().Dispatch(ctx, &Event{EventEnvelope: event.EventEnvelope, Message: message}); err != nil && !errors.Is(err, context.Canceled) {
+		facades.Log().Error("Event handler failed: "+err.Error(), map[string]interface{}{
+			"event_type": event.EventType,
+			"event_id":   event.EventID,
+		})
+	}
+}
+
+// Close does nothing; published events are kept
+func (b *MemoryBroker) Close() error {
+	return nil
+}
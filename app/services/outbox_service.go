@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/goravel/framework/contracts/database/orm"
+	"github.com/goravel/framework/facades"
+)
+
+// Outbox event statuses
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusFailed  = "failed"
+)
+
+// RecordEvent stores an event in the outbox. The query should be the
+// transaction that writes the change the event describes, so that the event
+// is persisted if and only if the change is committed.
+func RecordEvent(tx orm.Query, eventType string, payload interface{}, trace TraceContext) error {
+	envelope, err := NewEventEnvelope(eventType, payload, trace.CorrelationID)
+	if err != nil {
+		return err
+	}
+	envelope.TraceParent = trace.TraceParent
+
+	return recordEnvelope(tx, envelope)
+}
+
+// recordEnvelope stores an already built envelope in the outbox
+func recordEnvelope(tx orm.Query, envelope *EventEnvelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		EventID:     envelope.EventID,
+		EventType:   envelope.EventType,
+		Payload:     string(data),
+		Status:      OutboxStatusPending,
+		AvailableAt: time.Now().UTC(),
+	})
+}
+
+// OutboxRelay drains pending outbox events to the event publisher
+type OutboxRelay struct {
+	publisher    EventPublisher
+	batchSize    int
+	maxAttempts  int
+	pollInterval time.Duration
+	backoff      time.Duration
+	maxBackoff   time.Duration
+}
+
+// NewOutboxRelay creates an outbox relay configured from kafka.outbox
+func NewOutboxRelay(publisher EventPublisher) *OutboxRelay {
+	return &OutboxRelay{
+		publisher:    publisher,
+		batchSize:    facades.Config().GetInt("kafka.outbox.batch_size", 100),
+		maxAttempts:  facades.Config().GetInt("kafka.outbox.max_attempts", 20),
+		pollInterval: time.Duration(facades.Config().GetInt("kafka.outbox.poll_interval_ms", 1000)) * time.Millisecond,
+		backoff:      time.Duration(facades.Config().GetInt("kafka.outbox.retry_backoff_ms", 1000)) * time.Millisecond,
+		maxBackoff:   time.Duration(facades.Config().GetInt("kafka.outbox.max_backoff_ms", 300000)) * time.Millisecond,
+	}
+}
+
+// DrainOutbox relays the events that are ready in the outbox through the
+// broker selected by kafka.driver, e.g. a MemoryBroker installed by a test
+func DrainOutbox(ctx context.Context) (int, error) {
+	publisher, err := Publisher()
+	if err != nil {
+		return 0, err
+	}
+	return NewOutboxRelay(publisher).Drain(ctx)
+}
+
+// Run relays outbox events until the context is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		sent, err := r.RelayBatch(ctx)
+		if err != nil && (lastErr == nil || err.Error() != lastErr.Error()) {
+			if errors.Is(err, ErrKafkaDisabled) {
+				facades.Log().Warning("Outbox relay paused: Kafka is disabled")
+			} else {
+				facades.Log().Error("Outbox relay batch failed: " + err.Error())
+			}
+		}
+		lastErr = err
+
+		// Keep draining without waiting while full batches are being sent
+		if err == nil && sent == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain relays batches until the outbox has no more events ready to send
+func (r *OutboxRelay) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		sent, err := r.RelayBatch(ctx)
+		total += sent
+		if err != nil || sent < r.batchSize {
+			return total, err
+		}
+	}
+}
+
+// outboxPublishTimeout bounds the publish of a single outbox event
+const outboxPublishTimeout = 10 * time.Second
+
+// RelayBatch publishes up to one batch of pending outbox events in insertion
+// order and returns the number of events sent. It stops at the first event that
+// fails or is still backing off, so events are never published out of order.
+//
+// The events are claimed in a short transaction by leasing them with
+// locked_until, then published outside it and marked one by one. Relays in
+// other processes skip a batch while its lease holds, and a lease left behind
+// by a crashed relay expires after the time its publishes could have taken.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	// Leave events untouched while Kafka is down so outages don't use up attempts
+	if !r.publisher.IsEnabled() {
+		return 0, ErrKafkaDisabled
+	}
+
+	events, err := r.claim()
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	query := facades.Orm().Query()
+	sent := 0
+	var markErr error
+	for i := range events {
+		event := &events[i]
+		if ctx.Err() != nil {
+			break
+		}
+
+		envelope, err := outboxEnvelope(query, event)
+		if err == nil {
+			publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+			err = r.publisher.Publish(publishCtx, envelope)
+			cancel()
+		}
+		if err != nil {
+			markErr = r.markFailedAttempt(query, event, err)
+			break
+		}
+
+		if markErr = r.markSent(query, event); markErr != nil {
+			break
+		}
+		sent++
+	}
+
+	// Hand back the rest of the claim after a failure or cancellation
+	if rest := events[sent:]; len(rest) > 0 {
+		if err := r.release(rest); err != nil && markErr == nil {
+			markErr = err
+		}
+	}
+	return sent, markErr
+}
+
+// claim leases the next events that are ready to publish
+func (r *OutboxRelay) claim() ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	err := facades.Orm().Transaction(func(tx orm.Query) error {
+		var events []models.OutboxEvent
+		if err := tx.LockForUpdate().
+			Where("status = ?", OutboxStatusPending).
+			OrderBy("id").
+			Limit(r.batchSize).
+			Find(&events); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		claimed = ClaimableOutboxEvents(events, now)
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(claimed))
+		for i, event := range claimed {
+			ids[i] = event.ID
+		}
+		lockedUntil := now.Add(time.Duration(len(claimed)) * outboxPublishTimeout).Add(time.Minute)
+		_, err := tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("locked_until", lockedUntil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// release ends the lease on events that were claimed but not published
+func (r *OutboxRelay) release(events []models.OutboxEvent) error {
+	ids := make([]uint, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	_, err := facades.Orm().Query().Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update(map[string]any{
+		"locked_until": nil,
+	})
+	return err
+}
+
+// ClaimableOutboxEvents returns the leading events, in the given order, that
+// are due and not leased by another relay. It stops at the first one that
+// isn't, as publishing the events behind it would break their order.
+func ClaimableOutboxEvents(events []models.OutboxEvent, now time.Time) []models.OutboxEvent {
+	for i, event := range events {
+		if event.AvailableAt.After(now) || (event.LockedUntil != nil && event.LockedUntil.After(now)) {
+			return events[:i]
+		}
+	}
+	return events
+}
+
+// outboxEnvelope restores the envelope stored with an outbox event. Rows
+// recorded before envelopes were stored hold only the event data, so they are
+// wrapped in a new envelope that keeps the row's event ID.
+func outboxEnvelope(tx orm.Query, event *models.OutboxEvent) (*EventEnvelope, error) {
+	envelope, err := DecodeEnvelope([]byte(event.Payload))
+	if err == nil && envelope.SchemaVersion == CurrentSchemaVersion {
+		return envelope, nil
+	}
+
+	envelope, err = NewEventEnvelope(event.EventType, json.RawMessage(event.Payload), "")
+	if err != nil {
+		return nil, err
+	}
+	if event.EventID != "" {
+		envelope.EventID = event.EventID
+	} else {
+		event.EventID = envelope.EventID
+		if _, err := tx.Model(event).Update("event_id", event.EventID); err != nil {
+			return nil, err
+		}
+	}
+	return envelope, nil
+}
+
+// markSent records a successful delivery
+func (r *OutboxRelay) markSent(tx orm.Query, event *models.OutboxEvent) error {
+	now := time.Now().UTC()
+	_, err := tx.Model(event).Update(map[string]any{
+		"status":       OutboxStatusSent,
+		"attempts":     event.Attempts + 1,
+		"last_error":   "",
+		"sent_at":      now,
+		"locked_until": nil,
+	})
+	return err
+}
+
+// markFailedAttempt schedules the next retry with exponential backoff, or marks
+// the event as failed once kafka.outbox.max_attempts is exhausted
+func (r *OutboxRelay) markFailedAttempt(tx orm.Query, event *models.OutboxEvent, publishErr error) error {
+	attempts := event.Attempts + 1
+	status := OutboxStatusPending
+	if r.maxAttempts > 0 && attempts >= r.maxAttempts {
+		status = OutboxStatusFailed
+		facades.Log().Error("Outbox event exhausted delivery attempts", map[string]interface{}{
+			"outbox_id":  event.ID,
+			"event_type": event.EventType,
+			"attempts":   attempts,
+			"error":      publishErr.Error(),
+		})
+	}
+
+	delay := r.backoff << min(attempts-1, 16)
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+
+	_, err := tx.Model(event).Update(map[string]any{
+		"status":       status,
+		"attempts":     attempts,
+		"last_error":   publishErr.Error(),
+		"available_at": time.Now().UTC().Add(delay),
+		"locked_until": nil,
+	})
+	return err
+}
+
+// RetryFailed moves failed outbox events back to pending so the relay picks them up again
+func (r *OutboxRelay) RetryFailed() (int64, error) {
+	res, err := facades.Orm().Query().Model(&models.OutboxEvent{}).
+		Where("status = ?", OutboxStatusFailed).
+		Update(map[string]any{
+			"status":       OutboxStatusPending,
+			"attempts":     0,
+			"available_at": time.Now().UTC(),
+		})
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"goravel/app/models"
+
+	"github.com/google/uuid"
+	"github.com/goravel/framework/facades"
+)
+
+// ProcessedEventStore remembers which event IDs have been handled so that
+// redelivered messages can be skipped
+type ProcessedEventStore struct {
+	retention time.Duration
+}
+
+// NewProcessedEventStore creates a store configured from kafka.dedup
+func NewProcessedEventStore() *ProcessedEventStore {
+	return &ProcessedEventStore{
+		retention: time.Duration(facades.Config().GetInt("kafka.dedup.retention_hours", 168)) * time.Hour,
+	}
+}
+
+// Trackable reports whether an event ID can be used for deduplication. Events
+// published before per-event IDs were introduced carry the app name instead.
+func (s *ProcessedEventStore) Trackable(eventID string) bool {
+	_, err := uuid.Parse(eventID)
+	return err == nil
+}
+
+// Claim records the event as being handled and reports whether this consumer
+// won it. The insert relies on the unique event_id key, so when two consumers
+// race on the same redelivered message only one of them gets true.
+func (s *ProcessedEventStore) Claim(eventID string, eventType string) (bool, error) {
+	err := facades.Orm().Query().Create(&models.ProcessedEvent{
+		EventID:     eventID,
+		EventType:   eventType,
+		ProcessedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release forgets a claimed event so that a later redelivery handles it again
+func (s *ProcessedEventStore) Release(eventID string) error {
+	_, err := facades.Orm().Query().Where("event_id = ?", eventID).Delete(&models.ProcessedEvent{})
+	return err
+}
+
+// Prune removes records older than the retention window and returns how many were deleted
+func (s *ProcessedEventStore) Prune() (int64, error) {
+	cutoff := time.Now().UTC().Add(-s.retention)
+	res, err := facades.Orm().Query().Where("processed_at < ?", cutoff).Delete(&models.ProcessedEvent{})
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, nil
+}
+
+// isDuplicateKeyError detects unique constraint violations across the supported drivers
+func isDuplicateKeyError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "duplicate") || strings.Contains(msg, "unique constraint")
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goravel/framework/facades"
+)
+
+// Schema types understood by Confluent-compatible registries
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+)
+
+// schemaRegistryContentType is the media type of registry requests
+const schemaRegistryContentType = "application/vnd.schemaregistry.v1+json"
+
+// RegisteredSchema is a schema stored in the registry
+type RegisteredSchema struct {
+	ID         int
+	SchemaType string
+	Schema     string
+}
+
+// SchemaRegistryError is an error response from the registry
+type SchemaRegistryError struct {
+	StatusCode int
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+func (e *SchemaRegistryError) Error() string {
+	return fmt.Sprintf("schema registry returned %d (error_code %d): %s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+// SchemaRegistryClient talks to a Confluent-compatible schema registry. Schema
+// IDs are cached per subject and schema, and schemas per ID, so each is
+// requested at most once per process.
+type SchemaRegistryClient struct {
+	baseURL      string
+	username     string
+	password     string
+	autoRegister bool
+	client       *http.Client
+
+	mu      sync.RWMutex
+	ids     map[string]int
+	schemas map[int]*RegisteredSchema
+}
+
+// NewSchemaRegistryClient creates a client for the registry at baseURL. With
+// autoRegister, schemas are registered on first use; otherwise they must
+// already exist under the subject.
+func NewSchemaRegistryClient(baseURL string, username string, password string, autoRegister bool, timeout time.Duration) *SchemaRegistryClient {
+	return &SchemaRegistryClient{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		username:     username,
+		password:     password,
+		autoRegister: autoRegister,
+		client:       &http.Client{Timeout: timeout},
+		ids:          map[string]int{},
+		schemas:      map[int]*RegisteredSchema{},
+	}
+}
+
+// getSchemaRegistryClient builds a client from kafka.schema_registry, or
+// returns nil when no registry URL is configured
+func getSchemaRegistryClient() *SchemaRegistryClient {
+	baseURL := facades.Config().GetString("kafka.schema_registry.url")
+	if baseURL == "" {
+		return nil
+	}
+	return NewSchemaRegistryClient(
+		baseURL,
+		facades.Config().GetString("kafka.schema_registry.username"),
+		facades.Config().GetString("kafka.schema_registry.password"),
+		facades.Config().GetBool("kafka.schema_registry.auto_register", true),
+		time.Duration(facades.Config().GetInt("kafka.schema_registry.timeout_ms", 5000))*time.Millisecond,
+	)
+}
+
+// SchemaID returns the ID of a schema under a subject, registering it first
+// when auto registration is enabled
+func (c *SchemaRegistryClient) SchemaID(ctx context.Context, subject string, schemaType string, schema string) (int, error) {
+	cacheKey := subject + "\x00" + schemaType + "\x00" + schema
+	c.mu.RLock()
+	id, ok := c.ids[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	// Registering an existing schema returns its ID, so it is safe to repeat.
+	// Without auto registration the schema is only looked up.
+	path := "/subjects/" + url.PathEscape(subject)
+	if c.autoRegister {
+		path += "/versions"
+	}
+
+	request := map[string]string{"schema": schema}
+	if schemaType != SchemaTypeAvro {
+		// AVRO is the registry default and is omitted for older registries
+		request["schemaType"] = schemaType
+	}
+
+	var response struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, path, request, &response); err != nil {
+		return 0, fmt.Errorf("failed to resolve schema for subject %q: %w", subject, err)
+	}
+
+	c.mu.Lock()
+	c.ids[cacheKey] = response.ID
+	c.schemas[response.ID] = &RegisteredSchema{ID: response.ID, SchemaType: schemaType, Schema: schema}
+	c.mu.Unlock()
+
+	return response.ID, nil
+}
+
+// Schema returns the schema with the given ID
+func (c *SchemaRegistryClient) Schema(ctx context.Context, id int) (*RegisteredSchema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var response struct {
+		SchemaType string `json:"schemaType"`
+		Schema     string `json:"schema"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+
+	schema = &RegisteredSchema{ID: id, SchemaType: response.SchemaType, Schema: response.Schema}
+	if schema.SchemaType == "" {
+		schema.SchemaType = SchemaTypeAvro
+	}
+
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+
+	return schema, nil
+}
+
+// do sends a registry request and decodes the JSON response into out
+func (c *SchemaRegistryClient) do(ctx context.Context, method string, path string, body any, out any) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", schemaRegistryContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", schemaRegistryContentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		registryErr := &SchemaRegistryError{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(registryErr)
+		return registryErr
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
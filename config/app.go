@@ -52,6 +52,12 @@ func init() {
 		// Application Debug Mode
 		"debug": config.Env("APP_DEBUG", false),
 
+		// Application Scheduler
+		//
+		// Whether this process runs the scheduled tasks in app/console/kernel.go.
+		// Enable it on exactly one instance so tasks don't run once per replica.
+		"run_schedule": config.Env("APP_RUN_SCHEDULE", false),
+
 		// Application Timezone
 		//
 		// Here you may specify the default timezone for your application.
@@ -7,10 +7,19 @@ import (
 func init() {
 	config := facades.Config()
 	config.Add("kafka", map[string]any{
-		// Kafka Profile - can be "local" or "aws_msk"
+		// Broker Driver - kafka, memory (events stay in the process and are delivered
+		// to the handlers there, for tests) or log (events are only written to the log)
+		"driver": config.Env("KAFKA_DRIVER", "kafka"),
+
+		// Kafka Profile - the name of an entry in "profiles" below
 		"profile": config.Env("KAFKA_PROFILE", "local"),
 
 		// Kafka Profiles Configuration
+		// Each profile sets bootstrap_servers, security_protocol (PLAINTEXT, SSL,
+		// SASL_PLAINTEXT or SASL_SSL) and optionally sasl_mechanism, sasl_username and
+		// sasl_password. Add an entry here to define a new environment. Each profile
+		// reads its brokers from its own variable, so KAFKA_BOOTSTRAP_SERVERS below
+		// is the only setting that applies to every profile.
 		"profiles": map[string]any{
 			"local": map[string]any{
 				"bootstrap_servers": "kafka:29092",
@@ -20,49 +29,178 @@ func init() {
 				"sasl_password":     "",
 			},
 			"aws_msk": map[string]any{
-				"bootstrap_servers": config.Env("KAFKA_BOOTSTRAP_SERVERS", "localhost:9092"),
+				"bootstrap_servers": config.Env("KAFKA_MSK_BOOTSTRAP_SERVERS", "localhost:9092"),
 				"security_protocol": "SASL_SSL",
 				"sasl_mechanism":    "SCRAM-SHA-512",
 				"sasl_username":     config.Env("KAFKA_SASL_USERNAME", ""),
 				"sasl_password":     config.Env("KAFKA_SASL_PASSWORD", ""),
 			},
+			"confluent_cloud": map[string]any{
+				"bootstrap_servers": config.Env("KAFKA_CONFLUENT_BOOTSTRAP_SERVERS", ""),
+				"security_protocol": "SASL_SSL",
+				"sasl_mechanism":    "PLAIN",
+				"sasl_username":     config.Env("KAFKA_SASL_USERNAME", ""), // API key
+				"sasl_password":     config.Env("KAFKA_SASL_PASSWORD", ""), // API secret
+			},
 		},
 
-		// Kafka Broker Configuration
-		"bootstrap_servers": config.Env("KAFKA_BOOTSTRAP_SERVERS", "kafka:29092"),
-		"security_protocol": config.Env("KAFKA_SECURITY_PROTOCOL", "PLAINTEXT"),
+		// Kafka Broker Configuration - overrides the profile when set.
+		// bootstrap_servers is a comma-separated list of host:port pairs.
+		"bootstrap_servers": config.Env("KAFKA_BOOTSTRAP_SERVERS", ""),
+		"security_protocol": config.Env("KAFKA_SECURITY_PROTOCOL", ""),
 
-		// SASL Configuration
-		"sasl_mechanism": config.Env("KAFKA_SASL_MECHANISM", "SCRAM-SHA-512"),
+		// SASL Configuration - overrides the profile when set. Used when the security
+		// protocol is SASL_PLAINTEXT or SASL_SSL. sasl_mechanism is one of PLAIN,
+		// SCRAM-SHA-256, SCRAM-SHA-512 or AWS_MSK_IAM.
+		"sasl_mechanism": config.Env("KAFKA_SASL_MECHANISM", ""),
 		"sasl_username":  config.Env("KAFKA_SASL_USERNAME", ""),
 		"sasl_password":  config.Env("KAFKA_SASL_PASSWORD", ""),
 
+		// AWS MSK IAM Configuration - credentials come from the AWS SDK's default chain:
+		// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, the shared credentials and
+		// config files, then the container or instance role
+		"aws_msk_iam": map[string]any{
+			"region":           config.Env("KAFKA_AWS_REGION", config.Env("AWS_REGION", "")),
+			"profile":          config.Env("AWS_PROFILE", ""),
+			"credentials_file": config.Env("AWS_SHARED_CREDENTIALS_FILE", ""), // defaults to ~/.aws/credentials
+		},
+
 		// Producer Configuration
-		"client_id":              config.Env("KAFKA_CLIENT_ID", "activity-service"),
-		"acks":                   config.Env("KAFKA_ACKS", -1), // -1 means all
-		"retries":                config.Env("KAFKA_RETRIES", 3),
-		"retry_backoff_ms":       config.Env("KAFKA_RETRY_BACKOFF_MS", 100),
-		"max_in_flight_requests": config.Env("KAFKA_MAX_IN_FLIGHT_REQUESTS", 5),
-		"request_timeout_ms":     config.Env("KAFKA_REQUEST_TIMEOUT_MS", 30000),
+		"client_id":            config.Env("KAFKA_CLIENT_ID", "activity-service"),
+		"acks":                 config.Env("KAFKA_ACKS", -1), // -1 means all
+		"retries":              config.Env("KAFKA_RETRIES", 3),
+		"retry_backoff_ms":     config.Env("KAFKA_RETRY_BACKOFF_MS", 100),
+		"request_timeout_ms":   config.Env("KAFKA_REQUEST_TIMEOUT_MS", 30000),
+		"retry_backoff_max_ms": config.Env("KAFKA_RETRY_BACKOFF_MAX_MS", 1000),
+
+		// Synchronous publishes sent at once by this process. This is not the Kafka
+		// producer's max.in.flight.requests.per.connection: the writer always sends
+		// one batch per partition at a time. The old variable name still works.
+		"max_concurrent_publishes": config.Env("KAFKA_MAX_CONCURRENT_PUBLISHES", config.Env("KAFKA_MAX_IN_FLIGHT_REQUESTS", 5)),
+
+		// Producer Batching - a batch is sent once it holds batch_size messages or
+		// batch_bytes bytes, or batch_timeout_ms after its first message
+		"batch_size":       config.Env("KAFKA_BATCH_SIZE", 100),
+		"batch_bytes":      config.Env("KAFKA_BATCH_BYTES", 1048576),
+		"batch_timeout_ms": config.Env("KAFKA_BATCH_TIMEOUT_MS", 10),
+		"compression":      config.Env("KAFKA_COMPRESSION", "none"), // none, gzip, snappy, lz4 or zstd
+
+		// Async publishing - PublishEvent returns without waiting for the brokers and
+		// failures are only logged. The outbox relay always publishes synchronously.
+		"async": config.Env("KAFKA_ASYNC", false),
+
+		// Serialization - json, avro or protobuf. Avro and Protobuf register the
+		// envelope schema under "<topic>-value" and need a schema registry.
+		"serializer": config.Env("KAFKA_SERIALIZER", "json"),
+		"schema_registry": map[string]any{
+			"url":           config.Env("KAFKA_SCHEMA_REGISTRY_URL", ""),
+			"username":      config.Env("KAFKA_SCHEMA_REGISTRY_USERNAME", ""),
+			"password":      config.Env("KAFKA_SCHEMA_REGISTRY_PASSWORD", ""),
+			"auto_register": config.Env("KAFKA_SCHEMA_REGISTRY_AUTO_REGISTER", true),
+			"timeout_ms":    config.Env("KAFKA_SCHEMA_REGISTRY_TIMEOUT_MS", 5000),
+		},
+
+		// Partitioning - events with the same key go to the same partition and are
+		// consumed in order. partitioner is murmur2 (Java client compatible), fnv1a,
+		// crc32 or round_robin. partition_keys maps event type patterns to a key:
+		// subject (the aggregate, e.g. "activities/42"), event_type, event_id or
+		// data.<field>. Unmatched event types are keyed by subject.
+		"partitioner": config.Env("KAFKA_PARTITIONER", "murmur2"),
+		"partition_keys": map[string]any{
+			"*": config.Env("KAFKA_PARTITION_KEY", "subject"),
+		},
 
 		// Topics Configuration
+		// activity_events_topic also receives event types that match no route
 		"activity_events_topic": config.Env("KAFKA_ACTIVITY_EVENTS_TOPIC", "activity-events"),
 
+		// Topic routing - event type patterns mapped to topics. A pattern is an
+		// exact event type, a prefix wildcard such as "bay_session.*", or "*".
+		// The most specific matching pattern wins.
+		"routes": map[string]any{
+			"activity.*":    config.Env("KAFKA_ACTIVITY_EVENTS_TOPIC", "activity-events"),
+			"bay_session.*": config.Env("KAFKA_BAY_SESSION_EVENTS_TOPIC", "bay-session-events"),
+		},
+
+		// Comma-separated topics consumed by kafka:consume-activities; every routed topic when empty
+		"consumer_topics": config.Env("KAFKA_CONSUMER_TOPICS", ""),
+
+		// Topic Layout used by kafka:topics:create for the routed and consumed topics
+		// and their retry and dead-letter topics. overrides is keyed by topic name,
+		// e.g. "activity-events": map[string]any{"partitions": 12}.
+		"topics": map[string]any{
+			"partitions":               config.Env("KAFKA_TOPIC_PARTITIONS", 3),
+			"replication_factor":       config.Env("KAFKA_TOPIC_REPLICATION_FACTOR", 1),
+			"retention_ms":             config.Env("KAFKA_TOPIC_RETENTION_MS", 604800000),        // 7 days
+			"dead_letter_retention_ms": config.Env("KAFKA_DEAD_LETTER_RETENTION_MS", 2592000000), // 30 days
+			"overrides":                map[string]any{},
+		},
+
 		// Consumer Configuration
 		"consumer_group_id":       config.Env("KAFKA_CONSUMER_GROUP_ID", "activity-service-consumers"),
 		"auto_offset_reset":       config.Env("KAFKA_AUTO_OFFSET_RESET", "earliest"),
-		"enable_auto_commit":      config.Env("KAFKA_ENABLE_AUTO_COMMIT", true),
+		"enable_auto_commit":      config.Env("KAFKA_ENABLE_AUTO_COMMIT", true), // false commits only after handling
 		"auto_commit_interval_ms": config.Env("KAFKA_AUTO_COMMIT_INTERVAL_MS", 1000),
 		"max_poll_records":        config.Env("KAFKA_MAX_POLL_RECORDS", 500),
 		"session_timeout_ms":      config.Env("KAFKA_SESSION_TIMEOUT_MS", 45000),
 		"heartbeat_interval_ms":   config.Env("KAFKA_HEARTBEAT_INTERVAL_MS", 3000),
+		"consumer_workers":        config.Env("KAFKA_CONSUMER_WORKERS", 4),
+		"consumer_max_in_flight":  config.Env("KAFKA_CONSUMER_MAX_IN_FLIGHT", 100),
+
+		// How long the consumer waits for in-flight messages after SIGINT/SIGTERM
+		"consumer_shutdown_timeout_ms": config.Env("KAFKA_CONSUMER_SHUTDOWN_TIMEOUT_MS", 25000),
+
+		// Outbox Relay Configuration
+		"outbox": map[string]any{
+			"batch_size":       config.Env("KAFKA_OUTBOX_BATCH_SIZE", 100),
+			"poll_interval_ms": config.Env("KAFKA_OUTBOX_POLL_INTERVAL_MS", 1000),
+			"max_attempts":     config.Env("KAFKA_OUTBOX_MAX_ATTEMPTS", 20), // 0 retries forever
+			"retry_backoff_ms": config.Env("KAFKA_OUTBOX_RETRY_BACKOFF_MS", 1000),
+			"max_backoff_ms":   config.Env("KAFKA_OUTBOX_MAX_BACKOFF_MS", 300000),
+		},
+
+		// Reconnect Configuration
+		// When the brokers are unreachable at startup, publishing is paused and
+		// events are buffered in the outbox until a background check succeeds
+		"reconnect": map[string]any{
+			"enabled":            config.Env("KAFKA_RECONNECT_ENABLED", true),
+			"initial_backoff_ms": config.Env("KAFKA_RECONNECT_INITIAL_BACKOFF_MS", 1000),
+			"max_backoff_ms":     config.Env("KAFKA_RECONNECT_MAX_BACKOFF_MS", 60000),
+			"flush_on_reconnect": config.Env("KAFKA_RECONNECT_FLUSH_ON_RECONNECT", true),
+		},
+
+		// Metrics Configuration
+		// Writer and reader stats, broker health and consumer lag are refreshed every
+		// interval_ms. /metrics is served apart from the public API: on server_addr by
+		// the HTTP server and on consumer_addr by kafka:consume-activities (empty disables it).
+		"metrics": map[string]any{
+			"interval_ms":   config.Env("KAFKA_METRICS_INTERVAL_MS", 10000),
+			"server_addr":   config.Env("KAFKA_METRICS_SERVER_ADDR", ":9463"),
+			"consumer_addr": config.Env("KAFKA_METRICS_CONSUMER_ADDR", ":9464"),
+		},
+
+		// Consumer Retry and Dead-Letter Configuration
+		// Failed messages go to "<topic>.retry.<n>" after each delay, then to "<topic>.dlq"
+		"retry": map[string]any{
+			"enabled":                  config.Env("KAFKA_RETRY_ENABLED", true),
+			"delays_ms":                config.Env("KAFKA_RETRY_DELAYS_MS", "5000,30000,300000"),
+			"retry_topic_suffix":       config.Env("KAFKA_RETRY_TOPIC_SUFFIX", ".retry"),
+			"dead_letter_topic_suffix": config.Env("KAFKA_DEAD_LETTER_TOPIC_SUFFIX", ".dlq"),
+		},
+
+		// Consumer Deduplication Configuration
+		"dedup": map[string]any{
+			"retention_hours": config.Env("KAFKA_DEDUP_RETENTION_HOURS", 168),
+		},
 
-		// SSL Configuration (for AWS MSK or other SASL_SSL connections)
+		// SSL Configuration (for SSL and SASL_SSL connections)
+		// Broker certificates are always verified; the system roots are used when ca_location is empty
 		"ssl": map[string]any{
 			"ca_location":          config.Env("KAFKA_SSL_CA_LOCATION", ""),
 			"certificate_location": config.Env("KAFKA_SSL_CERTIFICATE_LOCATION", ""),
 			"key_location":         config.Env("KAFKA_SSL_KEY_LOCATION", ""),
 			"key_password":         config.Env("KAFKA_SSL_KEY_PASSWORD", ""),
+			"server_name":          config.Env("KAFKA_SSL_SERVER_NAME", ""), // defaults to the broker host
 		},
 	})
 }
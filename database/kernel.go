@@ -19,6 +19,9 @@ func (kernel Kernel) Migrations() []schema.Migration {
 		&migrations.M20250101000004CreateBaySessionsTable{},
 		&migrations.M20250101000005CreateBaySessionPlayersTable{},
 		&migrations.M20251204152204CreateBaySessionLocationsTable{},
+		&migrations.M20261017090001CreateOutboxEventsTable{},
+		&migrations.M20261017090003CreateProcessedEventsTable{},
+		&migrations.M20261017090004CreateActivityRevisionsTable{},
 	}
 }
 
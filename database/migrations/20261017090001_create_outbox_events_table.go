@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261017090001CreateOutboxEventsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261017090001CreateOutboxEventsTable) Signature() string {
+	return "20261017090001_create_outbox_events_table"
+}
+
+// Up Run the migrations.
+func (r *M20261017090001CreateOutboxEventsTable) Up() error {
+	return facades.Schema().Create("outbox_events", func(table schema.Blueprint) {
+		table.ID("id")
+		table.Uuid("event_id").Nullable()
+		table.String("event_type")
+		table.LongText("payload")
+		table.String("status").Default("pending")
+		table.UnsignedInteger("attempts").Default(0)
+		table.Text("last_error").Nullable()
+		table.DateTimeTz("available_at")
+		table.DateTimeTz("sent_at").Nullable()
+		table.DateTimeTz("locked_until").Nullable()
+		table.TimestampsTz()
+		table.Index("status", "id")
+		table.Unique("event_id")
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20261017090001CreateOutboxEventsTable) Down() error {
+	return facades.Schema().DropIfExists("outbox_events")
+}
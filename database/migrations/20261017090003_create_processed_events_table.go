@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261017090003CreateProcessedEventsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261017090003CreateProcessedEventsTable) Signature() string {
+	return "20261017090003_create_processed_events_table"
+}
+
+// Up Run the migrations.
+func (r *M20261017090003CreateProcessedEventsTable) Up() error {
+	return facades.Schema().Create("processed_events", func(table schema.Blueprint) {
+		table.ID("id")
+		table.Uuid("event_id")
+		table.String("event_type")
+		table.DateTimeTz("processed_at")
+		table.Unique("event_id")
+		table.Index("processed_at")
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20261017090003CreateProcessedEventsTable) Down() error {
+	return facades.Schema().DropIfExists("processed_events")
+}
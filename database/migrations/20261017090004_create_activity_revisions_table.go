@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/goravel/framework/contracts/database/schema"
+	"github.com/goravel/framework/facades"
+)
+
+type M20261017090004CreateActivityRevisionsTable struct{}
+
+// Signature The unique signature for the migration.
+func (r *M20261017090004CreateActivityRevisionsTable) Signature() string {
+	return "20261017090004_create_activity_revisions_table"
+}
+
+// Up Run the migrations.
+func (r *M20261017090004CreateActivityRevisionsTable) Up() error {
+	return facades.Schema().Create("activity_revisions", func(table schema.Blueprint) {
+		table.ID("id")
+		table.UnsignedBigInteger("activity_id")
+		table.String("action")
+		table.Json("changes")
+		table.String("claimed_actor").Nullable()
+		table.DateTimeTz("created_at")
+		table.Index("activity_id", "id")
+	})
+}
+
+// Down Reverse the migrations.
+func (r *M20261017090004CreateActivityRevisionsTable) Down() error {
+	return facades.Schema().DropIfExists("activity_revisions")
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/goravel/framework/facades"
 
+	"goravel/app/services"
 	"goravel/bootstrap"
 )
 
@@ -25,12 +27,45 @@ func main() {
 		}
 	}()
 
+	// Start scheduled tasks by facades.Schedule() on the one instance that runs them.
+	runSchedule := facades.Config().GetBool("app.run_schedule")
+	if runSchedule {
+		go facades.Schedule().Run()
+	}
+
+	// Sample the Kafka metrics and health for /health, and serve the metrics on
+	// their own address, apart from the public API
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	publisher, err := services.Publisher()
+	if err != nil {
+		facades.Log().Error("Kafka metrics not started: " + err.Error())
+	}
+	if kafkaService, ok := publisher.(*services.KafkaService); ok {
+		kafkaService.StartSampling()
+		if addr := facades.Config().GetString("kafka.metrics.server_addr", ""); addr != "" {
+			go func() {
+				if err := kafkaService.ServeMetrics(metricsCtx, addr); err != nil {
+					facades.Log().Error("Kafka metrics server failed: " + err.Error())
+				}
+			}()
+		}
+	}
+
 	// Listen for the OS signal
 	go func() {
 		<-quit
 		if err := facades.Route().Shutdown(); err != nil {
 			facades.Log().Error("Route Shutdown error: " + err.Error())
 		}
+		stopMetrics()
+		if runSchedule {
+			if err := facades.Schedule().Shutdown(); err != nil {
+				facades.Log().Error("Schedule Shutdown error: " + err.Error())
+			}
+		}
+		if err := services.CloseBroker(); err != nil {
+			facades.Log().Error("Broker Close error: " + err.Error())
+		}
 
 		os.Exit(0)
 	}()
@@ -21,6 +21,7 @@ func Api() {
 	facades.Route().Put("/api/activities/{id}", activityController.Update)
 	facades.Route().Patch("/api/activities/{id}", activityController.Update)
 	facades.Route().Delete("/api/activities/{id}", activityController.Destroy)
+	facades.Route().Get("/api/activities/{id}/history", activityController.History)
 
 	// Bay Session endpoints
 	baySessionController := controllers.NewBaySessionController()
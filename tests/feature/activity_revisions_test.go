@@ -0,0 +1,97 @@
+package feature
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type ActivityRevisionsTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	activity models.Activity
+}
+
+func TestActivityRevisionsTestSuite(t *testing.T) {
+	suite.Run(t, new(ActivityRevisionsTestSuite))
+}
+
+func (s *ActivityRevisionsTestSuite) SetupTest() {
+	startedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	s.activity = models.Activity{
+		Name:      "Morning round",
+		Type:      "golf",
+		Status:    "active",
+		Metadata:  models.JSONMap{"holes": float64(18)},
+		StartedAt: &startedAt,
+	}
+	s.activity.ID = 42
+}
+
+func (s *ActivityRevisionsTestSuite) TestDiffListsOnlyChangedFields() {
+	updated := s.activity
+	updated.Status = "completed"
+	updated.Metadata = models.JSONMap{"holes": float64(9)}
+	completedAt := time.Date(2026, 10, 17, 13, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	updated.CompletedAt = &completedAt
+	// The same instant in another zone is not a change
+	startedAt := s.activity.StartedAt.In(time.FixedZone("CEST", 2*60*60))
+	updated.StartedAt = &startedAt
+
+	changes := services.DiffActivity(&s.activity, &updated)
+
+	s.Equal(models.FieldChanges{
+		"status":       {Old: "active", New: "completed"},
+		"metadata":     {Old: map[string]interface{}{"holes": float64(18)}, New: map[string]interface{}{"holes": float64(9)}},
+		"completed_at": {Old: nil, New: "2026-10-17T11:30:00Z"},
+	}, changes)
+}
+
+func (s *ActivityRevisionsTestSuite) TestDiffForCreateAndDelete() {
+	created := services.DiffActivity(nil, &s.activity)
+	s.Equal(models.FieldChange{Old: nil, New: "Morning round"}, created["name"])
+	s.Equal(models.FieldChange{Old: nil, New: "2026-10-17T09:00:00Z"}, created["started_at"])
+	s.NotContains(created, "description")
+	s.NotContains(created, "completed_at")
+
+	deleted := services.DiffActivity(&s.activity, nil)
+	s.Equal(models.FieldChange{Old: "golf", New: nil}, deleted["type"])
+	s.Len(deleted, len(created))
+}
+
+func (s *ActivityRevisionsTestSuite) TestUpdatedEventCarriesTheDiff() {
+	updated := s.activity
+	updated.Name = "Evening round"
+	changes := services.DiffActivity(&s.activity, &updated)
+
+	envelope, err := services.NewEventEnvelope("activity.updated", services.NewActivityUpdatedPayload(updated, changes), "")
+	s.Require().NoError(err)
+	s.Equal("activities/42", envelope.Subject)
+
+	var data map[string]any
+	s.Require().NoError(json.Unmarshal(envelope.Data, &data))
+	s.Equal("Evening round", data["name"])
+	s.Equal(map[string]any{"name": map[string]any{"old": "Morning round", "new": "Evening round"}}, data["changes"])
+
+	// Consumers that only know the activity fields still decode the payload
+	var payload services.ActivityPayload
+	s.Require().NoError(envelope.DecodeData(&payload))
+	s.Equal(uint(42), payload.ID)
+}
+
+func (s *ActivityRevisionsTestSuite) TestChangesRoundTripThroughTheDatabaseValue() {
+	changes := models.FieldChanges{"status": {Old: "active", New: "completed"}}
+
+	value, err := changes.Value()
+	s.Require().NoError(err)
+
+	var scanned models.FieldChanges
+	s.Require().NoError(scanned.Scan(value))
+	s.Equal(changes, scanned)
+}
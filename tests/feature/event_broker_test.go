@@ -0,0 +1,174 @@
+package feature
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/jobs"
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type EventBrokerTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	broker *services.MemoryBroker
+	driver string
+}
+
+func TestEventBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(EventBrokerTestSuite))
+}
+
+func (s *EventBrokerTestSuite) SetupTest() {
+	s.driver = facades.Config().GetString("kafka.driver")
+	s.broker = services.NewMemoryBroker()
+	services.SetBroker(s.broker)
+}
+
+func (s *EventBrokerTestSuite) TearDownTest() {
+	facades.Config().Add("kafka.driver", s.driver)
+	services.SetBroker(nil)
+}
+
+func (s *EventBrokerTestSuite) TestSelectsBrokerFromConfig() {
+	services.SetBroker(nil)
+	facades.Config().Add("kafka.driver", services.BrokerDriverMemory)
+	publisher, err := services.Publisher()
+	s.Require().NoError(err)
+	s.IsType(&services.MemoryBroker{}, publisher)
+
+	services.SetBroker(nil)
+	facades.Config().Add("kafka.driver", services.BrokerDriverLog)
+	publisher, err = services.Publisher()
+	s.Require().NoError(err)
+	s.IsType(&services.LogBroker{}, publisher)
+	s.NoError(publisher.PublishEvent("activity.created", map[string]any{"id": 1}))
+}
+
+func (s *EventBrokerTestSuite) TestRejectsUnknownDriver() {
+	services.SetBroker(nil)
+	facades.Config().Add("kafka.driver", "memroy")
+
+	publisher, err := services.Publisher()
+	s.ErrorContains(err, `unsupported kafka.driver "memroy"`)
+	s.Nil(publisher)
+
+	job := &jobs.ActivityCreatedJob{Data: map[string]interface{}{"id": 1}}
+	s.ErrorContains(job.Handle(), `unsupported kafka.driver "memroy"`)
+}
+
+func (s *EventBrokerTestSuite) TestJobsPublishToTheBroker() {
+	job := &jobs.ActivityUpdatedJob{Data: map[string]interface{}{"id": 42, "name": "Morning round"}}
+
+	s.Require().NoError(job.Handle())
+
+	published := s.broker.PublishedOfType("activity.updated")
+	s.Require().Len(published, 1)
+	s.Equal("activity-events", published[0].Topic)
+
+	var data map[string]any
+	s.Require().NoError(published[0].DecodeData(&data))
+	s.Equal("Morning round", data["name"])
+	s.Empty(s.broker.PublishedOfType("activity.created"))
+}
+
+func (s *EventBrokerTestSuite) TestConsumeDispatchesToRegisteredHandlers() {
+	received := make(chan *services.Event, 2)
+	services.EventHandlers().Register("memory_broker_test.ping", func(ctx context.Context, event *services.Event) error {
+		received <- event
+		return nil
+	})
+
+	// Events published before and after the consumer starts are both delivered
+	s.Require().NoError(s.broker.PublishEvent("memory_broker_test.ping", map[string]any{"n": 1}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.broker.Consume(ctx, []string{"activity-events"}) }()
+
+	s.Require().NoError(s.broker.PublishEvent("memory_broker_test.ping", map[string]any{"n": 2}))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-received:
+			s.Equal(event.EventID, event.Header(services.HeaderEventID))
+			s.Equal("activity-events", event.Message.Topic)
+		case <-time.After(time.Second):
+			s.FailNow("event was not delivered")
+		}
+	}
+
+	cancel()
+	s.NoError(<-done)
+}
+
+func (s *EventBrokerTestSuite) TestConsumeContinuesAfterReset() {
+	received := make(chan *services.Event, 3)
+	services.EventHandlers().Register("memory_broker_test.reset", func(ctx context.Context, event *services.Event) error {
+		received <- event
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.broker.Consume(ctx, []string{"activity-events"}) }()
+
+	receive := func() *services.Event {
+		select {
+		case event := <-received:
+			return event
+		case <-time.After(time.Second):
+			s.FailNow("event was not delivered")
+			return nil
+		}
+	}
+
+	s.Require().NoError(s.broker.PublishEvent("memory_broker_test.reset", map[string]any{"n": 1}))
+	s.Require().NoError(s.broker.PublishEvent("memory_broker_test.reset", map[string]any{"n": 2}))
+	receive()
+	receive()
+
+	// An event published after a reset is delivered although the log is shorter than before
+	s.broker.Reset()
+	s.Empty(s.broker.Published())
+	s.Require().NoError(s.broker.PublishEvent("memory_broker_test.reset", map[string]any{"n": 3}))
+
+	var data map[string]any
+	s.Require().NoError(receive().DecodeData(&data))
+	s.Equal(float64(3), data["n"])
+
+	cancel()
+	s.NoError(<-done)
+}
+
+func (s *EventBrokerTestSuite) TestControllerEventsReachTheBroker() {
+	if !s.DatabaseReachable() {
+		s.T().Skip("no database is reachable")
+	}
+	s.RefreshDatabase()
+
+	response, err := s.Http(s.T()).
+		WithHeader("Content-Type", "application/json").
+		Post("/api/activities", strings.NewReader(`{"name":"Morning round","type":"golf"}`))
+	s.Require().NoError(err)
+	response.AssertCreated()
+
+	// Controllers write to the outbox; relaying it publishes to the installed broker
+	s.Empty(s.broker.Published())
+	sent, err := services.DrainOutbox(context.Background())
+	s.Require().NoError(err)
+	s.Equal(1, sent)
+
+	published := s.broker.PublishedOfType("activity.created")
+	s.Require().Len(published, 1)
+
+	var data map[string]any
+	s.Require().NoError(published[0].DecodeData(&data))
+	s.Equal("Morning round", data["name"])
+}
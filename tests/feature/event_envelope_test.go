@@ -0,0 +1,72 @@
+package feature
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type EventEnvelopeTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestEventEnvelopeTestSuite(t *testing.T) {
+	suite.Run(t, new(EventEnvelopeTestSuite))
+}
+
+func (s *EventEnvelopeTestSuite) TestRoundTrip() {
+	activity := models.Activity{Name: "Morning round", Type: "golf", Status: "active"}
+	activity.ID = 42
+
+	envelope, err := services.NewEventEnvelope("activity.created", services.NewActivityPayload(activity), "corr-1")
+	s.Require().NoError(err)
+	s.Equal(services.CurrentSchemaVersion, envelope.SchemaVersion)
+	s.Equal("activities/42", envelope.Subject)
+	s.Equal("corr-1", envelope.CorrelationID)
+	s.NotEmpty(envelope.EventID)
+
+	value, err := json.Marshal(envelope)
+	s.Require().NoError(err)
+
+	decoded, err := services.DecodeEnvelope(value)
+	s.Require().NoError(err)
+	s.Equal(envelope.EventID, decoded.EventID)
+
+	var payload services.ActivityPayload
+	s.Require().NoError(decoded.DecodeData(&payload))
+	s.Equal(uint(42), payload.ID)
+	s.Equal("Morning round", payload.Name)
+}
+
+func (s *EventEnvelopeTestSuite) TestDecodesVersionOne() {
+	value := []byte(`{
+		"event_type": "activity.deleted",
+		"event_id": "Activity Service",
+		"timestamp": "2025-01-01T10:30:00Z",
+		"data": {"id": 7, "name": "Legacy", "metadata": {"bay": 3}}
+	}`)
+
+	envelope, err := services.DecodeEnvelope(value)
+	s.Require().NoError(err)
+	s.Equal(1, envelope.SchemaVersion)
+	s.Equal("activity.deleted", envelope.EventType)
+	s.Equal(time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC), envelope.OccurredAt)
+
+	var payload services.ActivityPayload
+	s.Require().NoError(envelope.DecodeData(&payload))
+	s.Equal(uint(7), payload.ID)
+	s.Equal(float64(3), payload.Metadata["bay"])
+}
+
+func (s *EventEnvelopeTestSuite) TestRejectsNewerVersions() {
+	_, err := services.DecodeEnvelope([]byte(`{"schema_version": 99, "event_type": "activity.created", "data": {}}`))
+	s.True(errors.Is(err, services.ErrUnsupportedSchemaVersion))
+}
@@ -0,0 +1,100 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type EventHandlerRegistryTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestEventHandlerRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(EventHandlerRegistryTestSuite))
+}
+
+func (s *EventHandlerRegistryTestSuite) event(eventType string) *services.Event {
+	return &services.Event{EventEnvelope: &services.EventEnvelope{EventType: eventType}}
+}
+
+func (s *EventHandlerRegistryTestSuite) TestDispatchRunsMatchingHandlersInOrder() {
+	registry := services.NewEventHandlerRegistry()
+	var calls []string
+	record := func(name string) services.EventHandler {
+		return func(ctx context.Context, event *services.Event) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	registry.Register("bay_session.player_joined", record("billing"), record("loyalty"))
+	registry.Register("bay_session.*", record("projection"))
+	registry.Register("activity.created", record("unrelated"))
+
+	s.NoError(registry.Dispatch(context.Background(), s.event("bay_session.player_joined")))
+	s.Equal([]string{"billing", "loyalty", "projection"}, calls)
+}
+
+func (s *EventHandlerRegistryTestSuite) TestMiddlewareWrapsHandlers() {
+	registry := services.NewEventHandlerRegistry()
+	var calls []string
+	trace := func(name string) services.EventMiddleware {
+		return func(next services.EventHandler) services.EventHandler {
+			return func(ctx context.Context, event *services.Event) error {
+				calls = append(calls, name)
+				return next(ctx, event)
+			}
+		}
+	}
+
+	registry.Use(trace("outer"), trace("inner"))
+	registry.Register("activity.created", func(ctx context.Context, event *services.Event) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	s.NoError(registry.Dispatch(context.Background(), s.event("activity.created")))
+	s.Equal([]string{"outer", "inner", "handler"}, calls)
+}
+
+func (s *EventHandlerRegistryTestSuite) TestFailuresAndPanicsAreReturned() {
+	registry := services.NewEventHandlerRegistry()
+	registry.Use(services.RecoveryMiddleware())
+
+	failed := errors.New("projection failed")
+	ran := false
+	registry.Register("activity.deleted",
+		func(ctx context.Context, event *services.Event) error { return failed },
+		func(ctx context.Context, event *services.Event) error { panic("boom") },
+		func(ctx context.Context, event *services.Event) error { ran = true; return nil },
+	)
+
+	err := registry.Dispatch(context.Background(), s.event("activity.deleted"))
+	s.ErrorIs(err, failed)
+	s.ErrorContains(err, "boom")
+	s.True(ran)
+}
+
+func (s *EventHandlerRegistryTestSuite) TestEveryPublishedEventTypeHasHandlers() {
+	for _, eventType := range []string{
+		"activity.created",
+		"activity.updated",
+		"activity.deleted",
+		"bay_session.created",
+		"bay_session.updated",
+		"bay_session.deleted",
+		"bay_session.player_joined",
+		"bay_session.player_left",
+		"bay_session.player_purged",
+		"bay_session.player_restored",
+	} {
+		s.NotEmpty(services.EventHandlers().Handlers(eventType), eventType)
+	}
+}
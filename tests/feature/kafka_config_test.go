@@ -0,0 +1,90 @@
+package feature
+
+import (
+	"testing"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+// kafkaConnectionOverrides replace the active profile's settings when set, so
+// the tests clear them to see the profiles themselves
+var kafkaConnectionOverrides = []string{
+	"kafka.bootstrap_servers",
+	"kafka.security_protocol",
+	"kafka.sasl_mechanism",
+	"kafka.sasl_username",
+	"kafka.sasl_password",
+}
+
+type KafkaConfigTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	saved map[string]string
+}
+
+func TestKafkaConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaConfigTestSuite))
+}
+
+func (s *KafkaConfigTestSuite) SetupTest() {
+	s.saved = map[string]string{}
+	for _, key := range kafkaConnectionOverrides {
+		s.saved[key] = facades.Config().GetString(key)
+		facades.Config().Add(key, "")
+	}
+	s.saved["kafka.profile"] = facades.Config().GetString("kafka.profile")
+}
+
+func (s *KafkaConfigTestSuite) TearDownTest() {
+	for key, value := range s.saved {
+		facades.Config().Add(key, value)
+	}
+}
+
+func (s *KafkaConfigTestSuite) TestReadsProfileFromConfig() {
+	facades.Config().Add("kafka.profiles.staging", map[string]any{
+		"bootstrap_servers": "broker-1:9096, broker-2:9096",
+		"security_protocol": "SASL_SSL",
+		"sasl_mechanism":    "SCRAM-SHA-256",
+		"sasl_username":     "staging",
+		"sasl_password":     "secret",
+	})
+	facades.Config().Add("kafka.profile", "staging")
+
+	config, err := services.LoadKafkaConfig()
+
+	s.Require().NoError(err)
+	s.Equal("staging", config.Profile)
+	s.Equal([]string{"broker-1:9096", "broker-2:9096"}, config.Brokers())
+	s.Equal("SASL_SSL", config.SecurityProtocol)
+	s.Equal("SCRAM-SHA-256", config.SASLMechanism)
+	s.Equal("staging", config.SASLUsername)
+}
+
+func (s *KafkaConfigTestSuite) TestReportsUnknownProfile() {
+	facades.Config().Add("kafka.profile", "stagign")
+
+	config, err := services.LoadKafkaConfig()
+
+	s.ErrorContains(err, `unknown kafka.profile "stagign"`)
+	s.ErrorContains(err, "local")
+	s.Empty(config.BootstrapServers)
+	s.Equal("activity-events", config.ActivityEventsTopic)
+}
+
+func (s *KafkaConfigTestSuite) TestStartOffsetFollowsAutoOffsetReset() {
+	for reset, expected := range map[string]int64{"earliest": kafka.FirstOffset, "Latest": kafka.LastOffset} {
+		offset, err := (&services.KafkaConfig{AutoOffsetReset: reset}).StartOffset()
+
+		s.Require().NoError(err)
+		s.Equal(expected, offset, reset)
+	}
+
+	_, err := (&services.KafkaConfig{AutoOffsetReset: "newest"}).StartOffset()
+	s.ErrorContains(err, `unsupported kafka.auto_offset_reset "newest"`)
+}
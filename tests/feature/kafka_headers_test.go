@@ -0,0 +1,61 @@
+package feature
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaHeadersTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaHeadersTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaHeadersTestSuite))
+}
+
+func (s *KafkaHeadersTestSuite) TestSetsStandardHeaders() {
+	envelope, err := services.NewEventEnvelope("activity.created", map[string]any{"id": 1}, "corr-1")
+	s.Require().NoError(err)
+	envelope.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	headers := services.MessageHeaders(&kafka.Message{Headers: services.EventHeaders(envelope, services.ContentTypeJSON)})
+
+	s.Equal(envelope.EventID, headers[services.HeaderEventID])
+	s.Equal("activity.created", headers[services.HeaderEventType])
+	s.Equal("2", headers[services.HeaderSchemaVersion])
+	s.Equal("application/json", headers[services.HeaderContentType])
+	s.Equal("corr-1", headers[services.HeaderCorrelationID])
+	s.Equal(envelope.TraceParent, headers[services.HeaderTraceParent])
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", headers[services.HeaderTraceID])
+	s.NotEmpty(headers[services.HeaderProducerHost])
+}
+
+func (s *KafkaHeadersTestSuite) TestOmitsMissingTraceHeaders() {
+	envelope, err := services.NewEventEnvelope("activity.created", map[string]any{"id": 1}, "")
+	s.Require().NoError(err)
+	envelope.TraceParent = "not-a-traceparent"
+
+	headers := services.MessageHeaders(&kafka.Message{Headers: services.EventHeaders(envelope, services.ContentTypeJSON)})
+
+	s.NotContains(headers, services.HeaderCorrelationID)
+	s.NotContains(headers, services.HeaderTraceID)
+}
+
+func (s *KafkaHeadersTestSuite) TestEventExposesHeaders() {
+	event := &services.Event{Message: &kafka.Message{Headers: []kafka.Header{
+		{Key: services.HeaderEventType, Value: []byte("bay_session.created")},
+		{Key: "x-attempt", Value: []byte("1")},
+		{Key: "x-attempt", Value: []byte("2")},
+	}}}
+
+	s.Equal("bay_session.created", event.Header(services.HeaderEventType))
+	s.Equal("2", event.Header("x-attempt"))
+	s.Equal("", event.Header(services.HeaderCorrelationID))
+	s.Len(event.Headers(), 2)
+}
@@ -0,0 +1,85 @@
+package feature
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaHealthTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaHealthTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaHealthTestSuite))
+}
+
+func (s *KafkaHealthTestSuite) TestExpandsTopicsWithRetryAndDeadLetterTopics() {
+	routed := []string{"bay-session-events", "activity-events"}
+	consumed := []string{"activity-events", "billing-events"}
+	policy := &services.RetryPolicy{
+		Enabled:               true,
+		Delays:                []time.Duration{5 * time.Second, 30 * time.Second},
+		RetryTopicSuffix:      ".retry",
+		DeadLetterTopicSuffix: ".dlq",
+	}
+
+	s.Equal([]string{
+		"activity-events",
+		"activity-events.dlq",
+		"activity-events.retry.1",
+		"activity-events.retry.2",
+		"bay-session-events",
+		"bay-session-events.dlq",
+		"bay-session-events.retry.1",
+		"bay-session-events.retry.2",
+		"billing-events",
+		"billing-events.dlq",
+		"billing-events.retry.1",
+		"billing-events.retry.2",
+	}, services.ExpandTopics(append(routed, consumed...), policy))
+
+	policy.Enabled = false
+	s.Equal([]string{"activity-events", "bay-session-events", "billing-events"},
+		services.ExpandTopics(append(routed, consumed...), policy))
+}
+
+func (s *KafkaHealthTestSuite) TestHealthTopicsMatchManagedTopics() {
+	kafkaService := services.GetKafkaService()
+
+	var managed []string
+	for _, spec := range kafkaService.ManagedTopics() {
+		managed = append(managed, spec.Name)
+	}
+
+	topics := kafkaService.HealthTopics()
+	s.ElementsMatch(managed, topics)
+	s.Contains(topics, "activity-events")
+	s.Contains(topics, "activity-events.dlq")
+	s.Contains(topics, "activity-events.retry.1")
+}
+
+func (s *KafkaHealthTestSuite) TestCheckHealthReportsUnreachableBrokers() {
+	kafkaService := services.GetKafkaService()
+	if kafkaService.IsEnabled() {
+		s.T().Skip("a Kafka broker is reachable")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	report, err := kafkaService.CheckHealth(ctx, kafkaService.HealthTopics())
+
+	s.Error(err)
+	s.Require().NotNil(report)
+	s.False(report.Healthy)
+	s.Empty(report.Brokers)
+	s.Empty(report.Topics)
+	s.False(report.CheckedAt.IsZero())
+}
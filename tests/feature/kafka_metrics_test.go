@@ -0,0 +1,71 @@
+package feature
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaMetricsTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaMetricsTestSuite))
+}
+
+func (s *KafkaMetricsTestSuite) TestServesPrometheusMetrics() {
+	recorder := httptest.NewRecorder()
+
+	services.GetKafkaService().MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	s.Equal(http.StatusOK, recorder.Code)
+	for _, name := range []string{
+		"kafka_producer_messages_total",
+		"kafka_producer_errors_total",
+		"kafka_consumer_messages_total",
+		"kafka_consumer_bytes_total",
+		"kafka_consumer_rebalances_total",
+	} {
+		s.Contains(recorder.Body.String(), "# TYPE "+name+" counter")
+	}
+}
+
+func (s *KafkaMetricsTestSuite) TestSummaryWithoutBrokers() {
+	kafkaService := services.GetKafkaService()
+	if kafkaService.IsEnabled() {
+		s.T().Skip("a Kafka broker is reachable")
+	}
+
+	summary, err := kafkaService.MetricsSummary()
+
+	s.Require().NoError(err)
+	s.Zero(summary.Producer.Messages)
+	s.Zero(summary.PublishLatency.Count)
+	s.Nil(summary.ConsumerLag)
+}
+
+func (s *KafkaMetricsTestSuite) TestHealthIsEmptyWithoutBrokers() {
+	kafkaService := services.GetKafkaService()
+	if kafkaService.IsEnabled() {
+		s.T().Skip("a Kafka broker is reachable")
+	}
+
+	report, err := kafkaService.Health()
+
+	s.NoError(err)
+	s.Nil(report)
+}
+
+func (s *KafkaMetricsTestSuite) TestMetricsAreNotServedOnThePublicAPI() {
+	response, err := s.Http(s.T()).Get("/metrics")
+
+	s.Require().NoError(err)
+	response.AssertNotFound()
+}
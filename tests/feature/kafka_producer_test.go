@@ -0,0 +1,135 @@
+package feature
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaProducerTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaProducerTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaProducerTestSuite))
+}
+
+// producerConfig returns the default producer settings from config/kafka.go
+func (s *KafkaProducerTestSuite) producerConfig() *services.KafkaConfig {
+	return &services.KafkaConfig{
+		BootstrapServers:  "broker-1:9092,broker-2:9092",
+		Acks:              -1,
+		Retries:           3,
+		RetryBackoffMs:    100,
+		RetryBackoffMaxMs: 1000,
+		RequestTimeoutMs:  30000,
+		BatchSize:         100,
+		BatchBytes:        1048576,
+		BatchTimeoutMs:    10,
+		Compression:       "none",
+		Partitioner:       "murmur2",
+	}
+}
+
+func (s *KafkaProducerTestSuite) TestMapsSettingsOntoWriter() {
+	writer, err := services.NewWriter(s.producerConfig())
+	s.Require().NoError(err)
+
+	s.Equal("broker-1:9092,broker-2:9092", writer.Addr.String())
+	s.Equal(kafka.RequireAll, writer.RequiredAcks)
+	s.Equal(4, writer.MaxAttempts)
+	s.Equal(100*time.Millisecond, writer.WriteBackoffMin)
+	s.Equal(time.Second, writer.WriteBackoffMax)
+	s.Equal(100, writer.BatchSize)
+	s.Equal(int64(1048576), writer.BatchBytes)
+	s.Equal(10*time.Millisecond, writer.BatchTimeout)
+	s.Equal(30*time.Second, writer.ReadTimeout)
+	s.Equal(30*time.Second, writer.WriteTimeout)
+	s.Equal(kafka.Compression(0), writer.Compression)
+	s.IsType(kafka.Murmur2Balancer{}, writer.Balancer)
+	s.Empty(writer.Topic)
+	s.False(writer.Async)
+}
+
+func (s *KafkaProducerTestSuite) TestMapsAcks() {
+	for acks, expected := range map[int]kafka.RequiredAcks{
+		-1: kafka.RequireAll,
+		0:  kafka.RequireNone,
+		1:  kafka.RequireOne,
+	} {
+		cfg := s.producerConfig()
+		cfg.Acks = acks
+
+		writer, err := services.NewWriter(cfg)
+		s.Require().NoError(err)
+		s.Equal(expected, writer.RequiredAcks, acks)
+	}
+}
+
+func (s *KafkaProducerTestSuite) TestMapsCompression() {
+	for codec, expected := range map[string]kafka.Compression{
+		"":       0,
+		"none":   0,
+		"gzip":   kafka.Gzip,
+		"snappy": kafka.Snappy,
+		"LZ4":    kafka.Lz4,
+		"zstd":   kafka.Zstd,
+	} {
+		cfg := s.producerConfig()
+		cfg.Compression = codec
+
+		writer, err := services.NewWriter(cfg)
+		s.Require().NoError(err)
+		s.Equal(expected, writer.Compression, codec)
+	}
+}
+
+func (s *KafkaProducerTestSuite) TestMapsPartitioner() {
+	for partitioner, expected := range map[string]kafka.Balancer{
+		"":            kafka.Murmur2Balancer{},
+		"murmur2":     kafka.Murmur2Balancer{},
+		"fnv1a":       &kafka.Hash{},
+		"CRC32":       kafka.CRC32Balancer{},
+		"round_robin": &kafka.RoundRobin{},
+	} {
+		cfg := s.producerConfig()
+		cfg.Partitioner = partitioner
+
+		writer, err := services.NewWriter(cfg)
+		s.Require().NoError(err)
+		s.IsType(expected, writer.Balancer, partitioner)
+	}
+}
+
+func (s *KafkaProducerTestSuite) TestRejectsInvalidSettings() {
+	for name, test := range map[string]struct {
+		configure func(cfg *services.KafkaConfig)
+		err       string
+	}{
+		"acks": {
+			configure: func(cfg *services.KafkaConfig) { cfg.Acks = 2 },
+			err:       "unsupported kafka.acks 2 (expected -1, 0 or 1)",
+		},
+		"compression": {
+			configure: func(cfg *services.KafkaConfig) { cfg.Compression = "brotli" },
+			err:       `unsupported kafka.compression "brotli"`,
+		},
+		"partitioner": {
+			configure: func(cfg *services.KafkaConfig) { cfg.Partitioner = "sticky" },
+			err:       `unsupported kafka.partitioner "sticky"`,
+		},
+	} {
+		cfg := s.producerConfig()
+		test.configure(cfg)
+
+		writer, err := services.NewWriter(cfg)
+		s.ErrorContains(err, test.err, name)
+		s.Nil(writer, name)
+	}
+}
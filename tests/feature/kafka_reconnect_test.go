@@ -0,0 +1,90 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaReconnectTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	policy *services.ReconnectPolicy
+}
+
+func TestKafkaReconnectTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaReconnectTestSuite))
+}
+
+func (s *KafkaReconnectTestSuite) SetupTest() {
+	s.policy = &services.ReconnectPolicy{
+		Enabled:        true,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+	}
+}
+
+func (s *KafkaReconnectTestSuite) TestRetriesUntilTheBrokersAnswer() {
+	calls := 0
+	err := s.policy.Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 4 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal(4, calls)
+}
+
+func (s *KafkaReconnectTestSuite) TestStopsRetryingWhenClosed() {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- s.policy.Retry(ctx, func(ctx context.Context) error {
+			calls++
+			return errors.New("connection refused")
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		s.ErrorIs(err, context.Canceled)
+		s.Positive(calls)
+	case <-time.After(time.Second):
+		s.Fail("Retry did not stop after the context was cancelled")
+	}
+
+	// A service closed before the first attempt never probes
+	calls = 0
+	s.policy.InitialBackoff = time.Hour
+	s.ErrorIs(s.policy.Retry(ctx, func(ctx context.Context) error {
+		calls++
+		return nil
+	}), context.Canceled)
+	s.Zero(calls)
+}
+
+func (s *KafkaReconnectTestSuite) TestWaitForConnectionWaitsWhileReconnecting() {
+	kafkaService := services.GetKafkaService()
+	if kafkaService.IsEnabled() {
+		s.T().Skip("a Kafka broker is reachable")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := kafkaService.WaitForConnection(ctx)
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,35 @@
+package feature
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaReplayTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaReplayTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaReplayTestSuite))
+}
+
+func (s *KafkaReplayTestSuite) TestRejectsNonPositiveIdleTimeout() {
+	kafkaService := services.GetKafkaService()
+
+	for _, idleTimeout := range []time.Duration{0, -time.Second} {
+		result, err := kafkaService.Replay(context.Background(), services.ReplayOptions{
+			Topics:        []string{"activity-events"},
+			FromBeginning: true,
+			IdleTimeout:   idleTimeout,
+		})
+		s.EqualError(err, "replay idle timeout must be positive", idleTimeout)
+		s.Nil(result, idleTimeout)
+	}
+}
@@ -0,0 +1,141 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaRetryTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	policy *services.RetryPolicy
+	now    time.Time
+}
+
+func TestKafkaRetryTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaRetryTestSuite))
+}
+
+func (s *KafkaRetryTestSuite) SetupTest() {
+	s.policy = &services.RetryPolicy{
+		Enabled:               true,
+		Delays:                []time.Duration{5 * time.Second, 30 * time.Second},
+		RetryTopicSuffix:      ".retry",
+		DeadLetterTopicSuffix: ".dlq",
+	}
+	s.now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+}
+
+// header returns the value of the last header with the given key
+func (s *KafkaRetryTestSuite) header(msg kafka.Message, key string) string {
+	value := ""
+	for _, header := range msg.Headers {
+		if header.Key == key {
+			value = string(header.Value)
+		}
+	}
+	return value
+}
+
+func (s *KafkaRetryTestSuite) source() *kafka.Message {
+	return &kafka.Message{
+		Topic:     "activity-events",
+		Partition: 2,
+		Offset:    41,
+		Key:       []byte("activity-7"),
+		Value:     []byte(`{"id":7}`),
+		Headers:   []kafka.Header{{Key: services.HeaderEventID, Value: []byte("evt-1")}},
+	}
+}
+
+func (s *KafkaRetryTestSuite) TestForwardsToTheNextRetryTopic() {
+	forward, attempt := s.policy.Forward(s.source(), errors.New("db down"), s.now)
+
+	s.Equal(1, attempt)
+	s.Equal("activity-events.retry.1", forward.Topic)
+	s.Equal([]byte("activity-7"), forward.Key)
+	s.Equal([]byte(`{"id":7}`), forward.Value)
+	s.Equal("evt-1", s.header(forward, services.HeaderEventID))
+	s.Equal("activity-events", s.header(forward, services.HeaderOriginalTopic))
+	s.Equal("2", s.header(forward, services.HeaderOriginalPartition))
+	s.Equal("41", s.header(forward, services.HeaderOriginalOffset))
+	s.Equal("1", s.header(forward, services.HeaderAttempt))
+	s.Equal("db down", s.header(forward, services.HeaderErrorMessage))
+	s.Equal(s.now.Add(5*time.Second).Format(time.RFC3339Nano), s.header(forward, services.HeaderRetryAt))
+
+	// The second failure is read from the first retry topic and moves on to the second
+	retried := forward
+	retried.Topic, retried.Partition, retried.Offset = "activity-events.retry.1", 0, 3
+	forward, attempt = s.policy.Forward(&retried, errors.New("still down"), s.now)
+
+	s.Equal(2, attempt)
+	s.Equal("activity-events.retry.2", forward.Topic)
+	s.Equal("activity-events", s.header(forward, services.HeaderOriginalTopic))
+	s.Equal("41", s.header(forward, services.HeaderOriginalOffset))
+	s.Equal("still down", s.header(forward, services.HeaderErrorMessage))
+	s.Equal(s.now.Add(30*time.Second).Format(time.RFC3339Nano), s.header(forward, services.HeaderRetryAt))
+}
+
+func (s *KafkaRetryTestSuite) TestForwardsToTheDeadLetterTopicOnceDelaysRunOut() {
+	msg := s.source()
+	for range s.policy.Delays {
+		forward, _ := s.policy.Forward(msg, errors.New("db down"), s.now)
+		msg = &forward
+	}
+
+	forward, attempt := s.policy.Forward(msg, errors.New("gave up"), s.now)
+
+	s.Equal(3, attempt)
+	s.Equal("activity-events.dlq", forward.Topic)
+	s.Equal("gave up", s.header(forward, services.HeaderErrorMessage))
+	s.Empty(s.header(forward, services.HeaderRetryAt))
+
+	// Without delays a failure goes straight to the dead-letter topic
+	s.policy.Delays = nil
+	forward, _ = s.policy.Forward(s.source(), errors.New("db down"), s.now)
+	s.Equal("activity-events.dlq", forward.Topic)
+}
+
+func (s *KafkaRetryTestSuite) TestReplaysDeadLettersToTheSourceTopic() {
+	msg := s.source()
+	for i := 0; i <= len(s.policy.Delays); i++ {
+		forward, _ := s.policy.Forward(msg, errors.New("db down"), s.now)
+		msg = &forward
+	}
+	s.Require().Equal("activity-events.dlq", msg.Topic)
+
+	replay := s.policy.Replay(msg, "fallback-topic")
+
+	s.Equal("activity-events", replay.Topic)
+	s.Equal([]byte("activity-7"), replay.Key)
+	s.Equal([]byte(`{"id":7}`), replay.Value)
+	s.Equal([]kafka.Header{{Key: services.HeaderEventID, Value: []byte("evt-1")}}, replay.Headers)
+
+	// A dead letter without an original topic goes back to the replayed topic
+	s.Equal("fallback-topic", s.policy.Replay(&kafka.Message{Topic: "fallback-topic.dlq"}, "fallback-topic").Topic)
+}
+
+func (s *KafkaRetryTestSuite) TestRetryWaitsUntilTheRetryTime() {
+	forward, _ := s.policy.Forward(s.source(), errors.New("db down"), s.now)
+
+	s.Equal(5*time.Second, services.RetryWait(&forward, s.now))
+	s.Equal(2*time.Second, services.RetryWait(&forward, s.now.Add(3*time.Second)))
+	s.Zero(services.RetryWait(&forward, s.now.Add(time.Minute)))
+	s.Zero(services.RetryWait(s.source(), s.now))
+}
+
+func (s *KafkaRetryTestSuite) TestReplayDeadLettersRejectsNonPositiveIdleTimeout() {
+	for _, idleTimeout := range []time.Duration{0, -time.Second} {
+		replayed, err := services.GetKafkaService().ReplayDeadLetters(context.Background(), "activity-events", 0, idleTimeout)
+		s.EqualError(err, "dead letter replay idle timeout must be positive", idleTimeout)
+		s.Zero(replayed, idleTimeout)
+	}
+}
@@ -0,0 +1,69 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/goravel/framework/facades"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaSASLTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaSASLTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaSASLTestSuite))
+}
+
+func (s *KafkaSASLTestSuite) TestSelectsConfiguredMechanism() {
+	for _, name := range []string{"PLAIN", "SCRAM-SHA-256", "scram-sha-512"} {
+		mechanism, err := services.NewSASLMechanism(&services.KafkaConfig{
+			SASLMechanism: name,
+			SASLUsername:  "user",
+			SASLPassword:  "secret",
+		})
+
+		s.Require().NoError(err, name)
+		s.Equal(strings.ToUpper(name), mechanism.Name())
+	}
+}
+
+func (s *KafkaSASLTestSuite) TestRejectsUnknownMechanismAndMissingCredentials() {
+	_, err := services.NewSASLMechanism(&services.KafkaConfig{SASLMechanism: "GSSAPI"})
+	s.ErrorContains(err, "unsupported kafka.sasl_mechanism")
+
+	_, err = services.NewSASLMechanism(&services.KafkaConfig{SASLMechanism: "PLAIN"})
+	s.ErrorContains(err, "kafka.sasl_username and kafka.sasl_password are required")
+}
+
+func (s *KafkaSASLTestSuite) TestAWSMSKIAMSignsConnectRequest() {
+	s.T().Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	s.T().Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	facades.Config().Add("kafka.aws_msk_iam.region", "us-east-1")
+
+	mechanism, err := services.NewSASLMechanism(&services.KafkaConfig{SASLMechanism: "AWS_MSK_IAM"})
+	s.Require().NoError(err)
+	s.Equal("AWS_MSK_IAM", mechanism.Name())
+
+	ctx := sasl.WithMetadata(context.Background(), &sasl.Metadata{Host: "b-1.msk.example.com", Port: 9098})
+	_, response, err := mechanism.Start(ctx)
+	s.Require().NoError(err)
+
+	var payload map[string]string
+	s.Require().NoError(json.Unmarshal(response, &payload))
+	s.Equal("2020_10_22", payload["version"])
+	s.Equal("b-1.msk.example.com", payload["host"])
+	s.Equal("kafka-cluster:Connect", payload["action"])
+	s.Equal("AWS4-HMAC-SHA256", payload["x-amz-algorithm"])
+	s.Contains(payload["x-amz-credential"], "AKIDEXAMPLE/")
+	s.Contains(payload["x-amz-credential"], "/us-east-1/kafka-cluster/aws4_request")
+	s.NotEmpty(payload["x-amz-signature"])
+}
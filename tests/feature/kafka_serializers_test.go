@@ -0,0 +1,188 @@
+package feature
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+// registryStub is an in-process Confluent-compatible schema registry
+type registryStub struct {
+	mu            sync.Mutex
+	schemas       []map[string]string
+	registrations int
+	lookups       int
+}
+
+func (r *registryStub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	switch {
+	case req.Method == http.MethodPost && strings.HasPrefix(req.URL.Path, "/subjects/"):
+		var body map[string]string
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		if body["schemaType"] == "" {
+			body["schemaType"] = "AVRO"
+		}
+
+		for i, schema := range r.schemas {
+			if schema["schema"] == body["schema"] && schema["schemaType"] == body["schemaType"] {
+				_ = json.NewEncoder(w).Encode(map[string]int{"id": i + 1})
+				return
+			}
+		}
+		if !strings.HasSuffix(req.URL.Path, "/versions") {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40403, "message": "Schema not found"})
+			return
+		}
+
+		r.registrations++
+		r.schemas = append(r.schemas, body)
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": len(r.schemas)})
+	case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/schemas/ids/"):
+		r.lookups++
+		id, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/schemas/ids/"))
+		if id < 1 || id > len(r.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40403, "message": "Schema not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(r.schemas[id-1])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type KafkaSerializersTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	stub     *registryStub
+	server   *httptest.Server
+	envelope *services.EventEnvelope
+}
+
+func TestKafkaSerializersTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaSerializersTestSuite))
+}
+
+func (s *KafkaSerializersTestSuite) SetupTest() {
+	s.stub = &registryStub{}
+	s.server = httptest.NewServer(s.stub)
+
+	activity := models.Activity{Name: "Morning round", Type: "golf", Status: "active"}
+	activity.ID = 42
+	envelope, err := services.NewEventEnvelope("activity.created", services.NewActivityPayload(activity), "corr-1")
+	s.Require().NoError(err)
+	envelope.OccurredAt = envelope.OccurredAt.Truncate(time.Microsecond)
+	s.envelope = envelope
+}
+
+func (s *KafkaSerializersTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *KafkaSerializersTestSuite) registry(autoRegister bool) *services.SchemaRegistryClient {
+	return services.NewSchemaRegistryClient(s.server.URL, "", "", autoRegister, time.Second)
+}
+
+func (s *KafkaSerializersTestSuite) assertRoundTrip(serializer services.Serializer) []byte {
+	ctx := context.Background()
+
+	value, err := serializer.Serialize(ctx, "activity-events", s.envelope)
+	s.Require().NoError(err)
+	s.Equal(byte(0), value[0])
+	s.Equal(uint32(1), binary.BigEndian.Uint32(value[1:5]))
+
+	decoded, err := serializer.Deserialize(ctx, "activity-events", value)
+	s.Require().NoError(err)
+	s.Equal(s.envelope.EventID, decoded.EventID)
+	s.Equal(s.envelope.EventType, decoded.EventType)
+	s.Equal(s.envelope.CorrelationID, decoded.CorrelationID)
+	s.Equal(s.envelope.Subject, decoded.Subject)
+	s.True(s.envelope.OccurredAt.Equal(decoded.OccurredAt))
+
+	var payload services.ActivityPayload
+	s.Require().NoError(decoded.DecodeData(&payload))
+	s.Equal(uint(42), payload.ID)
+
+	return value
+}
+
+func (s *KafkaSerializersTestSuite) TestAvroRoundTripWithRegistry() {
+	serializer, err := services.NewAvroSerializer(s.registry(true))
+	s.Require().NoError(err)
+
+	s.assertRoundTrip(serializer)
+	s.Equal("AVRO", s.stub.schemas[0]["schemaType"])
+}
+
+func (s *KafkaSerializersTestSuite) TestProtobufRoundTripWithRegistry() {
+	serializer, err := services.NewProtobufSerializer(s.registry(true))
+	s.Require().NoError(err)
+
+	value := s.assertRoundTrip(serializer)
+	s.Equal(byte(0), value[5], "message index list for the first message")
+	s.Equal("PROTOBUF", s.stub.schemas[0]["schemaType"])
+	s.Contains(s.stub.schemas[0]["schema"], "message EventEnvelope {")
+}
+
+func (s *KafkaSerializersTestSuite) TestCachesSchemaIDs() {
+	ctx := context.Background()
+	registry := s.registry(true)
+	serializer, err := services.NewAvroSerializer(registry)
+	s.Require().NoError(err)
+
+	for i := 0; i < 3; i++ {
+		_, err := serializer.Serialize(ctx, "activity-events", s.envelope)
+		s.Require().NoError(err)
+	}
+	s.Equal(1, s.stub.registrations)
+
+	// A second process resolves the same ID and fetches the schema once to decode
+	value, err := serializer.Serialize(ctx, "activity-events", s.envelope)
+	s.Require().NoError(err)
+	reader, err := services.NewAvroSerializer(s.registry(false))
+	s.Require().NoError(err)
+	for i := 0; i < 2; i++ {
+		_, err := reader.Deserialize(ctx, "activity-events", value)
+		s.Require().NoError(err)
+	}
+	s.Equal(1, s.stub.lookups)
+}
+
+func (s *KafkaSerializersTestSuite) TestRequiresRegisteredSchemaWithoutAutoRegister() {
+	serializer, err := services.NewAvroSerializer(s.registry(false))
+	s.Require().NoError(err)
+
+	_, err = serializer.Serialize(context.Background(), "activity-events", s.envelope)
+
+	var registryErr *services.SchemaRegistryError
+	s.Require().ErrorAs(err, &registryErr)
+	s.Equal(40403, registryErr.ErrorCode)
+	s.Equal(0, s.stub.registrations)
+}
+
+func (s *KafkaSerializersTestSuite) TestRejectsUnframedValues() {
+	serializer, err := services.NewAvroSerializer(s.registry(true))
+	s.Require().NoError(err)
+
+	_, err = serializer.Deserialize(context.Background(), "activity-events", []byte(`{"event_type":"activity.created"}`))
+
+	s.ErrorIs(err, services.ErrInvalidWireFormat)
+}
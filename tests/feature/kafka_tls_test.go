@@ -0,0 +1,112 @@
+package feature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/youmark/pkcs8"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaTLSTestSuite struct {
+	suite.Suite
+	tests.TestCase
+	dir string
+}
+
+func TestKafkaTLSTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaTLSTestSuite))
+}
+
+func (s *KafkaTLSTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+// writeCertificate writes a self-signed certificate and its key, encrypted
+// with password when one is given, and returns their paths
+func (s *KafkaTLSTestSuite) writeCertificate(password string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "activity-service"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+
+	keyDER, err := pkcs8.MarshalPrivateKey(key, []byte(password), nil)
+	s.Require().NoError(err)
+	keyType := "PRIVATE KEY"
+	if password != "" {
+		keyType = "ENCRYPTED PRIVATE KEY"
+	}
+
+	certPath := filepath.Join(s.dir, "client.pem")
+	keyPath := filepath.Join(s.dir, "client.key")
+	s.Require().NoError(os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	s.Require().NoError(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: keyType, Bytes: keyDER}), 0o600))
+	return certPath, keyPath
+}
+
+func (s *KafkaTLSTestSuite) TestVerifiesBrokersByDefault() {
+	config, err := services.NewTLSConfig(services.TLSSettings{ServerName: "broker.internal"})
+
+	s.Require().NoError(err)
+	s.False(config.InsecureSkipVerify)
+	s.Equal("broker.internal", config.ServerName)
+	s.Nil(config.RootCAs)
+}
+
+func (s *KafkaTLSTestSuite) TestLoadsCABundleAndEncryptedClientKey() {
+	certPath, keyPath := s.writeCertificate("secret")
+
+	config, err := services.NewTLSConfig(services.TLSSettings{
+		CALocation:          certPath,
+		CertificateLocation: certPath,
+		KeyLocation:         keyPath,
+		KeyPassword:         "secret",
+	})
+
+	s.Require().NoError(err)
+	s.NotNil(config.RootCAs)
+	s.Len(config.Certificates, 1)
+}
+
+func (s *KafkaTLSTestSuite) TestRejectsWrongKeyPassword() {
+	certPath, keyPath := s.writeCertificate("secret")
+
+	_, err := services.NewTLSConfig(services.TLSSettings{
+		CertificateLocation: certPath,
+		KeyLocation:         keyPath,
+		KeyPassword:         "wrong",
+	})
+
+	s.ErrorContains(err, "kafka.ssl.key_location")
+}
+
+func (s *KafkaTLSTestSuite) TestReportsMissingFiles() {
+	_, err := services.NewTLSConfig(services.TLSSettings{CALocation: filepath.Join(s.dir, "missing-ca.pem")})
+	s.ErrorContains(err, "kafka.ssl.ca_location")
+	s.ErrorIs(err, os.ErrNotExist)
+
+	certPath, _ := s.writeCertificate("")
+	_, err = services.NewTLSConfig(services.TLSSettings{CertificateLocation: certPath})
+	s.ErrorContains(err, "must be set together")
+}
@@ -0,0 +1,55 @@
+package feature
+
+import (
+	"testing"
+
+	"github.com/goravel/framework/facades"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaTopicsTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaTopicsTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaTopicsTestSuite))
+}
+
+func (s *KafkaTopicsTestSuite) TearDownTest() {
+	facades.Config().Add("kafka.topics.overrides", map[string]any{})
+}
+
+func (s *KafkaTopicsTestSuite) TestUsesConfiguredDefaults() {
+	spec := services.TopicSpecFor("activity-events")
+
+	s.Equal("activity-events", spec.Name)
+	s.Equal(facades.Config().GetInt("kafka.topics.partitions"), spec.Partitions)
+	s.Equal(facades.Config().GetInt("kafka.topics.replication_factor"), spec.ReplicationFactor)
+	s.Equal(int64(facades.Config().GetInt("kafka.topics.retention_ms")), spec.RetentionMs)
+}
+
+func (s *KafkaTopicsTestSuite) TestUsesDeadLetterRetentionForDeadLetterTopics() {
+	suffix := facades.Config().GetString("kafka.retry.dead_letter_topic_suffix")
+
+	spec := services.TopicSpecFor("some-topic" + suffix)
+
+	s.Equal(int64(facades.Config().GetInt("kafka.topics.dead_letter_retention_ms")), spec.RetentionMs)
+	s.Equal(int64(facades.Config().GetInt("kafka.topics.retention_ms")), services.TopicSpecFor("some-topic").RetentionMs)
+}
+
+func (s *KafkaTopicsTestSuite) TestAppliesOverrideByTopicName() {
+	facades.Config().Add("kafka.topics.overrides", map[string]any{
+		"activity-events.retry.1": map[string]any{"partitions": 12, "retention_ms": "3600000"},
+	})
+
+	spec := services.TopicSpecFor("activity-events.retry.1")
+
+	s.Equal(12, spec.Partitions)
+	s.Equal(int64(3600000), spec.RetentionMs)
+	s.Equal(facades.Config().GetInt("kafka.topics.replication_factor"), spec.ReplicationFactor)
+	s.Equal(facades.Config().GetInt("kafka.topics.partitions"), services.TopicSpecFor("activity-events").Partitions)
+}
@@ -0,0 +1,132 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type KafkaWorkerPoolTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestKafkaWorkerPoolTestSuite(t *testing.T) {
+	suite.Run(t, new(KafkaWorkerPoolTestSuite))
+}
+
+func (s *KafkaWorkerPoolTestSuite) TestKeepsPerKeyOrderAcrossWorkers() {
+	var mu sync.Mutex
+	handled := map[string][]int64{}
+
+	pool := services.NewWorkerPool(4, 16, func(msg *kafka.Message) {
+		// Vary the handling time so workers finish out of order
+		time.Sleep(time.Duration(rand.Intn(300)) * time.Microsecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		subject := string(services.OrderingKey(msg))
+		handled[subject] = append(handled[subject], msg.Offset)
+	})
+
+	subjects := []string{"activities/1", "activities/2", "bay-sessions/3", "bay-sessions/4", "activities/5", "activities/6"}
+	for offset := int64(0); offset < 300; offset++ {
+		subject := subjects[offset%int64(len(subjects))]
+		s.Require().NoError(pool.Submit(context.Background(), &kafka.Message{
+			Offset:  offset,
+			Key:     []byte(fmt.Sprintf("key-%d", offset)),
+			Headers: []kafka.Header{{Key: services.HeaderSubject, Value: []byte(subject)}},
+		}))
+	}
+	pool.Close()
+
+	s.Len(handled, len(subjects))
+	for subject, offsets := range handled {
+		s.Len(offsets, 50, subject)
+		s.IsIncreasing(offsets, subject)
+	}
+}
+
+func (s *KafkaWorkerPoolTestSuite) TestOrderingKeyUsesSubjectHeaderThenMessageKey() {
+	// The value is never decoded, so Avro or Protobuf values are fine
+	withSubject := &kafka.Message{
+		Key:     []byte("activity-1"),
+		Value:   []byte{0, 0, 0, 0, 7, 2},
+		Headers: []kafka.Header{{Key: services.HeaderSubject, Value: []byte("activities/1")}},
+	}
+	s.Equal([]byte("activities/1"), services.OrderingKey(withSubject))
+
+	withoutSubject := &kafka.Message{Key: []byte("activity-1"), Value: []byte(`{"subject":"activities/1"}`)}
+	s.Equal([]byte("activity-1"), services.OrderingKey(withoutSubject))
+}
+
+func (s *KafkaWorkerPoolTestSuite) TestNeverCommitsPastAnUnfinishedOffset() {
+	tracker := services.NewOffsetTracker()
+	var commits []int64
+	commit := func(msg *kafka.Message) { commits = append(commits, msg.Offset) }
+	message := func(offset int64) *kafka.Message {
+		return &kafka.Message{Topic: "activity-events", Partition: 0, Offset: offset}
+	}
+
+	for offset := int64(10); offset < 15; offset++ {
+		tracker.Track(message(offset))
+	}
+	tracker.Track(&kafka.Message{Topic: "activity-events", Partition: 1, Offset: 3})
+
+	tracker.Complete(message(12), commit)
+	tracker.Complete(message(11), commit)
+	s.Empty(commits, "offset 10 is unfinished")
+
+	tracker.Complete(message(10), commit)
+	s.Equal([]int64{12}, commits)
+
+	tracker.Complete(message(14), commit)
+	s.Equal([]int64{12}, commits, "offset 13 is unfinished")
+
+	tracker.Complete(message(13), commit)
+	s.Equal([]int64{12, 14}, commits)
+
+	// Partition 0 is drained and forgotten; partition 1 is still pending
+	s.Equal(1, tracker.Partitions())
+	tracker.Complete(&kafka.Message{Topic: "activity-events", Partition: 1, Offset: 3}, commit)
+	s.Zero(tracker.Partitions())
+}
+
+func (s *KafkaWorkerPoolTestSuite) TestDropsPartitionStateWhenReassigned() {
+	tracker := services.NewOffsetTracker()
+	var commits []int64
+	commit := func(msg *kafka.Message) { commits = append(commits, msg.Offset) }
+	message := func(offset int64) *kafka.Message {
+		return &kafka.Message{Topic: "activity-events", Partition: 0, Offset: offset}
+	}
+
+	// Offset 5 fails and stays unfinished, holding back 6 and 7
+	for offset := int64(5); offset < 8; offset++ {
+		tracker.Track(message(offset))
+	}
+	tracker.Complete(message(6), commit)
+	tracker.Complete(message(7), commit)
+	s.Empty(commits)
+
+	// After a rebalance the partition is fetched again from its committed offset
+	tracker.Track(message(5))
+	tracker.Track(message(6))
+	tracker.Complete(message(5), commit)
+	s.Equal([]int64{5}, commits)
+	tracker.Complete(message(6), commit)
+	s.Equal([]int64{5, 6}, commits)
+	s.Zero(tracker.Partitions())
+
+	// A late completion from the dropped assignment commits nothing
+	tracker.Complete(message(7), commit)
+	s.Equal([]int64{5, 6}, commits)
+}
@@ -0,0 +1,69 @@
+package feature
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goravel/framework/database/orm"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type OutboxRelayTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestOutboxRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxRelayTestSuite))
+}
+
+func (s *OutboxRelayTestSuite) event(id uint, availableAt time.Time, lockedUntil *time.Time) models.OutboxEvent {
+	return models.OutboxEvent{Model: orm.Model{ID: id}, AvailableAt: availableAt, LockedUntil: lockedUntil}
+}
+
+func (s *OutboxRelayTestSuite) TestClaimsLeadingEventsThatAreDueAndUnleased() {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	ids := func(events []models.OutboxEvent) []uint {
+		result := []uint{}
+		for _, event := range events {
+			result = append(result, event.ID)
+		}
+		return result
+	}
+
+	s.Equal([]uint{1, 2, 3}, ids(services.ClaimableOutboxEvents([]models.OutboxEvent{
+		s.event(1, past, nil),
+		s.event(2, now, nil),
+		s.event(3, past, &past),
+	}, now)), "due events and expired leases are claimed")
+
+	s.Equal([]uint{1}, ids(services.ClaimableOutboxEvents([]models.OutboxEvent{
+		s.event(1, past, nil),
+		s.event(2, future, nil),
+		s.event(3, past, nil),
+	}, now)), "an event backing off holds back the events behind it")
+
+	s.Equal([]uint{}, ids(services.ClaimableOutboxEvents([]models.OutboxEvent{
+		s.event(1, past, &future),
+		s.event(2, past, nil),
+	}, now)), "a batch leased by another relay is skipped as a whole")
+}
+
+func (s *OutboxRelayTestSuite) TestLeavesEventsBufferedWhileKafkaIsDown() {
+	kafkaService := services.GetKafkaService()
+	if kafkaService.IsEnabled() {
+		s.T().Skip("a Kafka broker is reachable")
+	}
+
+	sent, err := services.NewOutboxRelay(kafkaService).Drain(context.Background())
+
+	s.ErrorIs(err, services.ErrKafkaDisabled)
+	s.Zero(sent)
+}
@@ -0,0 +1,63 @@
+package feature
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/models"
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type PartitionKeyTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestPartitionKeyTestSuite(t *testing.T) {
+	suite.Run(t, new(PartitionKeyTestSuite))
+}
+
+func (s *PartitionKeyTestSuite) envelope(eventType string, payload any) *services.EventEnvelope {
+	envelope, err := services.NewEventEnvelope(eventType, payload, "")
+	s.Require().NoError(err)
+	return envelope
+}
+
+func (s *PartitionKeyTestSuite) TestKeysBySubjectByDefault() {
+	resolver, err := services.NewPartitionKeyResolver(nil)
+	s.Require().NoError(err)
+
+	activity := models.Activity{Name: "Morning round"}
+	activity.ID = 42
+	created := s.envelope("activity.created", services.NewActivityPayload(activity))
+	deleted := s.envelope("activity.deleted", services.NewActivityPayload(activity))
+
+	s.Equal([]byte("activities/42"), resolver.Key(created))
+	s.Equal(resolver.Key(created), resolver.Key(deleted))
+	s.Nil(resolver.Key(s.envelope("activity.created", map[string]any{"id": 42})))
+}
+
+func (s *PartitionKeyTestSuite) TestUsesMostSpecificStrategy() {
+	resolver, err := services.NewPartitionKeyResolver(map[string]string{
+		"*":                  "event_type",
+		"bay_session.*":      "data.venue",
+		"bay_session.failed": "event_id",
+	})
+	s.Require().NoError(err)
+
+	s.Equal([]byte("activity.created"), resolver.Key(s.envelope("activity.created", map[string]any{"id": 1})))
+	s.Equal([]byte("north"), resolver.Key(s.envelope("bay_session.created", map[string]any{"venue": "north"})))
+	s.Equal([]byte("7"), resolver.Key(s.envelope("bay_session.updated", map[string]any{"venue": 7})))
+	s.Nil(resolver.Key(s.envelope("bay_session.updated", map[string]any{"venue": nil})))
+
+	failed := s.envelope("bay_session.failed", map[string]any{"venue": "north"})
+	s.Equal([]byte(failed.EventID), resolver.Key(failed))
+}
+
+func (s *PartitionKeyTestSuite) TestRejectsUnknownStrategy() {
+	_, err := services.NewPartitionKeyResolver(map[string]string{"activity.*": "activity_id"})
+
+	s.ErrorContains(err, `unsupported kafka.partition_keys strategy "activity_id"`)
+}
@@ -0,0 +1,42 @@
+package feature
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/services"
+	"goravel/tests"
+)
+
+type TopicRouterTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestTopicRouterTestSuite(t *testing.T) {
+	suite.Run(t, new(TopicRouterTestSuite))
+}
+
+func (s *TopicRouterTestSuite) TestMostSpecificRouteWins() {
+	router := services.NewTopicRouter(map[string]string{
+		"bay_session.*":             "bay-session-events",
+		"bay_session.player_*":      "bay-session-players",
+		"bay_session.player_purged": "compliance",
+		"*":                         "everything-else",
+	}, "activity-events")
+
+	s.Equal("bay-session-events", router.Route("bay_session.created"))
+	s.Equal("bay-session-players", router.Route("bay_session.player_joined"))
+	s.Equal("compliance", router.Route("bay_session.player_purged"))
+	s.Equal("everything-else", router.Route("activity.created"))
+}
+
+func (s *TopicRouterTestSuite) TestUnroutedEventsUseDefaultTopic() {
+	router := services.NewTopicRouter(map[string]string{
+		"bay_session.*": "bay-session-events",
+	}, "activity-events")
+
+	s.Equal("activity-events", router.Route("activity.created"))
+	s.Equal([]string{"activity-events", "bay-session-events"}, router.Topics())
+}
@@ -0,0 +1,91 @@
+package feature
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goravel/framework/facades"
+	"github.com/stretchr/testify/suite"
+
+	"goravel/app/http/requests"
+	"goravel/app/models"
+	"goravel/tests"
+)
+
+type UpdateActivityRequestTestSuite struct {
+	suite.Suite
+	tests.TestCase
+}
+
+func TestUpdateActivityRequestTestSuite(t *testing.T) {
+	suite.Run(t, new(UpdateActivityRequestTestSuite))
+}
+
+func (s *UpdateActivityRequestTestSuite) TestRules() {
+	validator, err := facades.Validation().Make(map[string]any{
+		"started_at":   "2026-10-17T09:00:00Z",
+		"completed_at": "2026-10-17T08:00:00+02:00",
+		"scheduled_at": "17/10/2026",
+		"metadata":     map[string]any{"notes": strings.Repeat("x", 20)},
+	}, map[string]string{
+		"started_at":   "rfc3339",
+		"completed_at": "rfc3339|not_before:started_at",
+		"scheduled_at": "rfc3339",
+		"metadata":     "max_json_bytes:16",
+	})
+	s.Require().NoError(err)
+
+	errs := validator.Errors()
+	s.Require().NotNil(errs)
+	s.False(errs.Has("started_at"))
+	s.Equal("The completed_at must not be before started_at.", errs.One("completed_at"))
+	s.True(errs.Has("scheduled_at"))
+	s.Equal("The metadata may not be larger than 16 bytes when encoded as JSON.", errs.One("metadata"))
+}
+
+func (s *UpdateActivityRequestTestSuite) TestInvalidFieldsAreRejectedPerField() {
+	body := `{"name": "", "status": "paused", "started_at": "2026-10-17T09:00:00Z",
+		"completed_at": "2026-10-17T08:59:59Z", "metadata": {"notes": "` + strings.Repeat("x", requests.MaxMetadataBytes) + `"}}`
+
+	response, err := s.Http(s.T()).WithHeader("Content-Type", "application/json").
+		Put("/api/activities/1", strings.NewReader(body))
+	s.Require().NoError(err)
+	response.AssertUnprocessableEntity()
+
+	content, err := response.Json()
+	s.Require().NoError(err)
+	s.Equal("Validation failed", content["error"])
+
+	errs, ok := content["errors"].(map[string]any)
+	s.Require().True(ok)
+	s.Len(errs, 4)
+	s.Contains(errs["name"], "required")
+	s.Contains(errs["status"], "in")
+	s.Contains(errs["completed_at"], "not_before")
+	s.Contains(errs["metadata"], "max_json_bytes")
+}
+
+func (s *UpdateActivityRequestTestSuite) TestCompletedAtIsCheckedAgainstTheStoredStart() {
+	startedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	activity := models.Activity{StartedAt: &startedAt}
+
+	var request requests.UpdateActivityRequest
+	completedAt := startedAt.Add(-time.Minute)
+	activity.CompletedAt = &completedAt
+	errs, err := request.ValidateActivity(&activity)
+	s.Require().NoError(err)
+	s.Require().NotNil(errs)
+	s.True(errs.Has("completed_at"))
+
+	completedAt = startedAt
+	errs, err = request.ValidateActivity(&activity)
+	s.Require().NoError(err)
+	s.Nil(errs)
+
+	activity.StartedAt = nil
+	completedAt = startedAt.Add(-time.Hour)
+	errs, err = request.ValidateActivity(&activity)
+	s.Require().NoError(err)
+	s.Nil(errs)
+}
@@ -1,6 +1,10 @@
 package tests
 
 import (
+	"net"
+	"time"
+
+	"github.com/goravel/framework/facades"
 	"github.com/goravel/framework/testing"
 
 	"goravel/bootstrap"
@@ -13,3 +17,17 @@ func init() {
 type TestCase struct {
 	testing.TestCase
 }
+
+// DatabaseReachable reports whether the default database connection accepts
+// connections, so tests that need one can skip without it
+func (r *TestCase) DatabaseReachable() bool {
+	connection := "database.connections." + facades.Config().GetString("database.default")
+	address := net.JoinHostPort(facades.Config().GetString(connection+".host"), facades.Config().GetString(connection+".port"))
+
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}